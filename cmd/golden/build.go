@@ -0,0 +1,133 @@
+// --- golden/cmd/golden/build.go ---
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// findOdin locates the Odin compiler: the -odin flag, then $ODIN, then
+// odin on PATH. Any executable that takes odin's arguments will do, so a
+// stub script can stand in for it in tests.
+func findOdin(flagValue string) (string, error) {
+	for _, candidate := range []string{flagValue, os.Getenv("ODIN")} {
+		if candidate == "" {
+			continue
+		}
+		path, err := exec.LookPath(candidate)
+		if err != nil {
+			return "", fmt.Errorf("odin compiler %q: %v", candidate, err)
+		}
+		return path, nil
+	}
+	path, err := exec.LookPath("odin")
+	if err != nil {
+		return "", errors.New("odin compiler not found on PATH; install Odin, or point $ODIN or -odin at it")
+	}
+	return path, nil
+}
+
+// defaultExe names the executable after the input file or directory.
+// An input named golden gets golden_main, since <out>/golden is the
+// runtime.
+func defaultExe(inputPath, outDir, target string) string {
+	if abs, err := filepath.Abs(inputPath); err == nil {
+		inputPath = abs // "." names it after the directory
+	}
+	name := strings.TrimSuffix(filepath.Base(inputPath), ".go")
+	if strings.EqualFold(name, "golden") {
+		name += "_main"
+	}
+	if strings.HasPrefix(target, "windows") || target == "" && runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(outDir, name)
+}
+
+// odinBuild compiles the package transpile wrote to pkgDir into exe.
+// main.odin imports the runtime by a path relative to itself, so it needs
+// no collection of its own.
+type odinBuild struct {
+	odin        string
+	pkgDir      string
+	exe         string
+	target      string            // odin -target; "" builds for the host
+	collections map[string]string // extra -collection:name=dir
+	verbose     bool
+}
+
+func (b *odinBuild) args() ([]string, error) {
+	runtimeDir, err := filepath.Abs(filepath.Join(b.pkgDir, "golden"))
+	if err != nil {
+		return nil, err
+	}
+	if exe, err := filepath.Abs(b.exe); err == nil && strings.EqualFold(exe, runtimeDir) {
+		return nil, fmt.Errorf("output %s would overwrite the golden runtime; choose another -o", b.exe)
+	}
+	args := []string{"build", b.pkgDir, "-out:" + b.exe}
+	names := make([]string, 0, len(b.collections))
+	for name := range b.collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, fmt.Sprintf("-collection:%s=%s", name, b.collections[name]))
+	}
+	if b.target != "" {
+		args = append(args, "-target:"+b.target)
+	}
+	return args, nil
+}
+
+// run invokes the compiler. Odin's diagnostics go to stderr as they are.
+func (b *odinBuild) run() error {
+	if dir := filepath.Dir(b.exe); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("could not create output dir: %v", err)
+		}
+	}
+	args, err := b.args()
+	if err != nil {
+		return err
+	}
+	if b.verbose {
+		fmt.Fprintf(os.Stderr, "%s %s\n", b.odin, strings.Join(args, " "))
+	}
+	cmd := exec.Command(b.odin, args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("odin build %s failed: %v", b.pkgDir, err)
+	}
+	return nil
+}
+
+// runProgram executes exe with args on the current terminal and returns
+// its exit status.
+func runProgram(exe string, args []string) int {
+	path, err := filepath.Abs(exe)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return exit.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "golden run: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,108 @@
+// --- golden/cmd/golden/build_test.go ---
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// stubOdin writes an executable shell script standing in for odin: it
+// records its arguments, one per line, in <dir>/argv and exits with
+// $STUB_EXIT. $ODIN points at it.
+func stubOdin(t *testing.T) (dir string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stub compiler is a shell script")
+	}
+	dir = t.TempDir()
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > \"$(dirname \"$0\")/argv\"\nexit ${STUB_EXIT:-0}\n"
+	stub := filepath.Join(dir, "odin")
+	if err := os.WriteFile(stub, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ODIN", stub)
+	return dir
+}
+
+func TestOdinBuildArgs(t *testing.T) {
+	dir := stubOdin(t)
+	odin, err := findOdin("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if odin != filepath.Join(dir, "odin") {
+		t.Fatalf("findOdin = %s, want the $ODIN stub", odin)
+	}
+
+	out := filepath.Join(dir, "out")
+	b := odinBuild{
+		odin:        odin,
+		pkgDir:      out,
+		exe:         defaultExe("hello.go", out, "linux_amd64"),
+		target:      "linux_amd64",
+		collections: map[string]string{"vendor": "/v", "lib": "/l"},
+	}
+	args, err := b.args()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"build", out,
+		"-out:" + filepath.Join(out, "hello"),
+		"-collection:lib=/l",
+		"-collection:vendor=/v",
+		"-target:linux_amd64",
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("args:\n got %q\nwant %q", args, want)
+	}
+
+	if err := b.run(); err != nil {
+		t.Fatal(err)
+	}
+	argv, err := os.ReadFile(filepath.Join(dir, "argv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Split(strings.TrimSuffix(string(argv), "\n"), "\n"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("stub saw:\n got %q\nwant %q", got, want)
+	}
+
+	t.Setenv("STUB_EXIT", "1")
+	if err := b.run(); err == nil {
+		t.Fatal("run succeeded although odin failed")
+	}
+}
+
+func TestDefaultExeAvoidsRuntime(t *testing.T) {
+	out := "out"
+	for _, input := range []string{"golden.go", filepath.Join("src", "golden")} {
+		if exe := defaultExe(input, out, "linux_amd64"); exe != filepath.Join(out, "golden_main") {
+			t.Errorf("defaultExe(%s) = %s, want %s", input, exe, filepath.Join(out, "golden_main"))
+		}
+	}
+	b := odinBuild{odin: "odin", pkgDir: out, exe: filepath.Join(out, "golden")}
+	if _, err := b.args(); err == nil {
+		t.Error("args accepted an -o on top of the runtime")
+	}
+}
+
+func TestRunProgramExitCode(t *testing.T) {
+	dir := stubOdin(t)
+	t.Setenv("STUB_EXIT", "3")
+	if code := runProgram(filepath.Join(dir, "odin"), []string{"a", "b"}); code != 3 {
+		t.Fatalf("runProgram = %d, want 3", code)
+	}
+	argv, err := os.ReadFile(filepath.Join(dir, "argv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(argv) != "a\nb\n" {
+		t.Fatalf("program saw %q, want its arguments", argv)
+	}
+}
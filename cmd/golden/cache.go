@@ -0,0 +1,166 @@
+// --- golden/cmd/golden/cache.go ---
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/v4rm4n/golden/transpiler"
+)
+
+// ── Output cache ─────────────────────────────────────────────────────────────
+//
+// <out>/.golden-cache.json remembers what the output directory was
+// generated from: a hash of the sources, the options and the golden
+// binary itself. When none of that changed and the outputs on disk are
+// still the ones recorded, transpile doesn't even parse, and the directory
+// keeps its mtimes, so whatever builds from it sees nothing new. Otherwise
+// only outputs whose content changed are rewritten.
+
+const cacheName = ".golden-cache.json"
+
+type outputCache struct {
+	Key          string            `json:"key"`
+	Outputs      map[string]string `json:"outputs"` // path relative to the output dir → sha256
+	Diagnostics  []string          `json:"diagnostics,omitempty"`
+	Untranslated bool              `json:"untranslated,omitempty"`
+}
+
+// sourceFiles lists the files transpile reads for input: the file itself,
+// or every .go file of a package directory but its tests.
+func sourceFiles(input string) ([]string, error) {
+	info, err := os.Stat(input)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{input}, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(input, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range matches {
+		if !isTestFile(f) {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// isTestFile reports whether a package file is a test, which golden
+// doesn't translate.
+func isTestFile(name string) bool {
+	return strings.HasSuffix(name, "_test.go")
+}
+
+var selfHash = sync.OnceValue(func() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	sum, err := hashFile(exe)
+	if err != nil {
+		return ""
+	}
+	return sum
+})
+
+// cacheKey hashes everything the output depends on. A different golden
+// binary may translate differently, so it counts too.
+func cacheKey(sources []string, opts transpiler.Options) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "golden %s\n%+v\n", selfHash(), opts)
+	for _, src := range sources {
+		sum, err := hashFile(src)
+		if err != nil {
+			return "", err
+		}
+		// The path as given, not just the name: the source map embeds it
+		fmt.Fprintf(h, "%s %s\n", src, sum)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// loadCache reads outDir's cache; a missing or unreadable one is empty.
+func loadCache(outDir string) *outputCache {
+	c := &outputCache{}
+	data, err := os.ReadFile(filepath.Join(outDir, cacheName))
+	if err != nil || json.Unmarshal(data, c) != nil {
+		return &outputCache{}
+	}
+	return c
+}
+
+// fresh reports whether outDir already holds the output for key.
+func (c *outputCache) fresh(outDir, key string) bool {
+	if c.Key != key || len(c.Outputs) == 0 {
+		return false
+	}
+	for rel, want := range c.Outputs {
+		got, err := hashFile(filepath.Join(outDir, rel))
+		if err != nil || got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// record notes the outputs now in outDir under key, and saves the cache
+// if that changed it.
+func (c *outputCache) record(outDir, key string, outputs []string, diags []transpiler.Diagnostic) error {
+	next := &outputCache{Key: key, Outputs: make(map[string]string), Untranslated: transpiler.HasErrors(diags)}
+	for _, path := range outputs {
+		rel, err := filepath.Rel(outDir, path)
+		if err != nil {
+			return err
+		}
+		if next.Outputs[filepath.ToSlash(rel)], err = hashFile(path); err != nil {
+			return err
+		}
+	}
+	for _, d := range diags {
+		next.Diagnostics = append(next.Diagnostics, d.String())
+	}
+	data, err := json.MarshalIndent(next, "", "  ")
+	if err != nil {
+		return err
+	}
+	*c = *next
+	_, err = writeIfChanged(filepath.Join(outDir, cacheName), append(data, '\n'))
+	return err
+}
+
+// writeIfChanged writes data to path unless it already holds exactly
+// that, and reports whether it wrote.
+func writeIfChanged(path string, data []byte) (bool, error) {
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, data) {
+		return false, nil
+	}
+	return true, writeFileAtomic(path, data)
+}
@@ -0,0 +1,78 @@
+// --- golden/cmd/golden/cache_test.go ---
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/v4rm4n/golden/transpiler"
+)
+
+func TestSourceFilesSkipTests(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"main.go", "util.go", "main_test.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := sourceFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "main.go"), filepath.Join(dir, "util.go")}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("sourceFiles = %q, want %q", files, want)
+	}
+
+	// Watch and the cache agree: editing a test changes neither
+	sig, err := sourceSignature(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main_test.go"), []byte("package main\n\n// edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := sourceSignature(dir); again != sig {
+		t.Error("editing a _test.go file changed the watch signature")
+	}
+}
+
+func TestTranspileSkipsUpToDateOutput(t *testing.T) {
+	dir := t.TempDir()
+	src, out := filepath.Join(dir, "main.go"), filepath.Join(dir, "out")
+	write := func(code string) {
+		if err := os.WriteFile(src, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(1)\n}\n")
+	if err := transpile(src, out, transpiler.Options{}, false, false); err != nil {
+		t.Fatal(err)
+	}
+	mainOdin := filepath.Join(out, "main.odin")
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(mainOdin, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := transpile(src, out, transpiler.Options{}, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if fi, _ := os.Stat(mainOdin); !fi.ModTime().Equal(old) {
+		t.Error("an up-to-date main.odin was rewritten")
+	}
+
+	write("package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(2)\n}\n")
+	if err := transpile(src, out, transpiler.Options{}, false, false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(mainOdin)
+	if err != nil || !strings.Contains(string(data), "fmt.println(2)") {
+		t.Errorf("main.odin not regenerated after an edit: %v", err)
+	}
+}
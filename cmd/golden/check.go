@@ -0,0 +1,332 @@
+// --- golden/cmd/golden/check.go ---
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/v4rm4n/golden/transpiler"
+)
+
+// ── golden check ─────────────────────────────────────────────────────────────
+//
+// Runs the translator over existing packages without writing anything and
+// reports how much of them it handles. Coverage counts statements and
+// package-level declarations; one is untranslated when an error
+// diagnostic falls inside it and no smaller one.
+
+type checkReport struct {
+	Packages   []*packageReport          `json:"packages"`
+	Statements int                       `json:"statements"`
+	Translated int                       `json:"translated"`
+	Coverage   float64                   `json:"coverage"`
+	Constructs map[string]map[string]int `json:"constructs"` // category → construct → count
+}
+
+type packageReport struct {
+	Path       string        `json:"path"`
+	Name       string        `json:"name,omitempty"`
+	Error      string        `json:"error,omitempty"` // it couldn't be parsed or checked
+	Files      []*fileReport `json:"files,omitempty"`
+	Statements int           `json:"statements"`
+	Translated int           `json:"translated"`
+	Coverage   float64       `json:"coverage"`
+}
+
+type fileReport struct {
+	File        string            `json:"file"`
+	Statements  int               `json:"statements"`
+	Translated  int               `json:"translated"`
+	Coverage    float64           `json:"coverage"`
+	Diagnostics []checkDiagnostic `json:"diagnostics,omitempty"`
+}
+
+type checkDiagnostic struct {
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	Severity  string `json:"severity"`
+	Category  string `json:"category"`
+	Construct string `json:"construct"`
+	Message   string `json:"message"`
+}
+
+func coverage(translated, total int) float64 {
+	if total == 0 {
+		return 1
+	}
+	return float64(translated) / float64(total)
+}
+
+// checkMain is `golden check [-json] [-v] [packages]`.
+func checkMain(args []string) {
+	fs := flag.NewFlagSet("golden check", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: golden check [-json] [-v] [dir | dir/... | file.go]...")
+		fs.PrintDefaults()
+	}
+	jsonFlag := fs.Bool("json", false, "print the report as JSON")
+	verboseFlag := fs.Bool("v", false, "list every diagnostic under its file")
+	fs.Parse(args)
+
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	targets, err := expandPatterns(patterns)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report := &checkReport{Constructs: make(map[string]map[string]int)}
+	for _, target := range targets {
+		for _, pkg := range checkTarget(target, report) {
+			report.Packages = append(report.Packages, pkg)
+			report.Statements += pkg.Statements
+			report.Translated += pkg.Translated
+		}
+	}
+	report.Coverage = coverage(report.Translated, report.Statements)
+
+	if *jsonFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	printCheckReport(report, *verboseFlag)
+}
+
+// expandPatterns turns dir/... into every directory below dir holding Go
+// files, skipping the ones the go tool ignores.
+func expandPatterns(patterns []string) ([]string, error) {
+	var targets []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			targets = append(targets, path)
+		}
+	}
+	for _, pattern := range patterns {
+		root, recursive := strings.CutSuffix(pattern, "...")
+		if !recursive {
+			if _, err := os.Stat(pattern); err != nil {
+				return nil, err
+			}
+			add(filepath.Clean(pattern))
+			continue
+		}
+		root = filepath.Clean(strings.TrimSuffix(root, "/"))
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			if matches, _ := filepath.Glob(filepath.Join(path, "*.go")); len(matches) > 0 {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return targets, nil
+}
+
+// checkTarget checks the packages in a directory, or a single file.
+func checkTarget(target string, report *checkReport) []*packageReport {
+	fset := token.NewFileSet()
+	info, err := os.Stat(target)
+	if err != nil {
+		return []*packageReport{{Path: target, Error: err.Error()}}
+	}
+	var pkgs map[string]*ast.Package
+	if info.IsDir() {
+		notTest := func(fi fs.FileInfo) bool { return !isTestFile(fi.Name()) }
+		pkgs, err = parser.ParseDir(fset, target, notTest, parser.ParseComments)
+	} else {
+		var f *ast.File
+		if f, err = parser.ParseFile(fset, target, nil, parser.ParseComments); err == nil {
+			pkgs = map[string]*ast.Package{f.Name.Name: {Name: f.Name.Name, Files: map[string]*ast.File{target: f}}}
+		}
+	}
+	if err != nil {
+		return []*packageReport{{Path: target, Error: err.Error()}}
+	}
+
+	names := make([]string, 0, len(pkgs))
+	for name := range pkgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var out []*packageReport
+	for _, name := range names {
+		out = append(out, checkPackage(target, fset, pkgs[name], report))
+	}
+	return out
+}
+
+func checkPackage(path string, fset *token.FileSet, pkg *ast.Package, report *checkReport) *packageReport {
+	pr := &packageReport{Path: path, Name: pkg.Name}
+	fileNames := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		fileNames = append(fileNames, name)
+	}
+	sort.Strings(fileNames)
+
+	tp := &transpiler.Package{Path: path, Fset: fset}
+	for _, name := range fileNames {
+		tp.Files = append(tp.Files, pkg.Files[name])
+	}
+	// Code the translator has never seen may crash it; that is a finding too
+	result, err := transpiler.New(transpiler.Config{}).Transpile(context.Background(), []*transpiler.Package{tp})
+	if err != nil {
+		pr.Error = err.Error()
+		return pr
+	}
+	diags := result.Diagnostics
+
+	byFile := make(map[string][]transpiler.Diagnostic)
+	for _, d := range diags {
+		byFile[d.Pos.Filename] = append(byFile[d.Pos.Filename], d)
+		if report.Constructs[string(d.Category)] == nil {
+			report.Constructs[string(d.Category)] = make(map[string]int)
+		}
+		report.Constructs[string(d.Category)][d.Construct]++
+	}
+	for _, name := range fileNames {
+		fr := checkFile(name, fset, pkg.Files[name], byFile[name])
+		pr.Files = append(pr.Files, fr)
+		pr.Statements += fr.Statements
+		pr.Translated += fr.Translated
+	}
+	pr.Coverage = coverage(pr.Translated, pr.Statements)
+	return pr
+}
+
+// checkFile counts f's statements and declarations, and those an error
+// leaves untranslated.
+func checkFile(name string, fset *token.FileSet, f *ast.File, diags []transpiler.Diagnostic) *fileReport {
+	type unit struct{ start, end int }
+	var units []unit
+	addUnit := func(n ast.Node) {
+		units = append(units, unit{fset.Position(n.Pos()).Offset, fset.Position(n.End()).Offset})
+	}
+	for _, decl := range f.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok != token.IMPORT {
+			for _, spec := range gd.Specs {
+				addUnit(spec)
+			}
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if stmt, ok := n.(ast.Stmt); ok {
+			if _, isBlock := stmt.(*ast.BlockStmt); !isBlock {
+				addUnit(stmt)
+			}
+		}
+		return true
+	})
+
+	fr := &fileReport{File: name, Statements: len(units)}
+	failed := make(map[int]bool)
+	for _, d := range diags {
+		fr.Diagnostics = append(fr.Diagnostics, checkDiagnostic{
+			Line:      d.Pos.Line,
+			Column:    d.Pos.Column,
+			Severity:  d.Severity.String(),
+			Category:  string(d.Category),
+			Construct: d.Construct,
+			Message:   d.Msg,
+		})
+		if d.Severity != transpiler.Error {
+			continue
+		}
+		innermost := -1
+		for i, u := range units {
+			if u.start <= d.Pos.Offset && d.Pos.Offset < u.end &&
+				(innermost < 0 || u.end-u.start < units[innermost].end-units[innermost].start) {
+				innermost = i
+			}
+		}
+		if innermost >= 0 {
+			failed[innermost] = true
+		}
+	}
+	fr.Translated = fr.Statements - len(failed)
+	fr.Coverage = coverage(fr.Translated, fr.Statements)
+	return fr
+}
+
+func printCheckReport(report *checkReport, verbose bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, pkg := range report.Packages {
+		if pkg.Error != "" {
+			fmt.Fprintf(w, "%s\terror: %s\n", pkg.Path, pkg.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s (%s)\t%d/%d\t%.1f%%\n", pkg.Path, pkg.Name, pkg.Translated, pkg.Statements, 100*pkg.Coverage)
+		for _, f := range pkg.Files {
+			fmt.Fprintf(w, "    %s\t%d/%d\t%.1f%%\n", filepath.Base(f.File), f.Translated, f.Statements, 100*f.Coverage)
+			if verbose {
+				for _, d := range f.Diagnostics {
+					sev := ""
+					if d.Severity == "warning" {
+						sev = "warning: "
+					}
+					fmt.Fprintf(w, "        %d:%d: %s%s\n", d.Line, d.Column, sev, d.Message)
+				}
+			}
+		}
+	}
+	w.Flush()
+
+	if len(report.Constructs) > 0 {
+		fmt.Println("\nUnsupported constructs:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		categories := make([]string, 0, len(report.Constructs))
+		for c := range report.Constructs {
+			categories = append(categories, c)
+		}
+		sort.Strings(categories)
+		for _, c := range categories {
+			constructs := make([]string, 0, len(report.Constructs[c]))
+			for k := range report.Constructs[c] {
+				constructs = append(constructs, k)
+			}
+			// Most frequent first
+			sort.Slice(constructs, func(i, j int) bool {
+				a, b := report.Constructs[c][constructs[i]], report.Constructs[c][constructs[j]]
+				return a > b || a == b && constructs[i] < constructs[j]
+			})
+			for _, k := range constructs {
+				fmt.Fprintf(w, "    %s\t%s\t%d\n", c, k, report.Constructs[c][k])
+			}
+		}
+		w.Flush()
+	}
+	fmt.Printf("\n%d/%d statements translated (%.1f%%) in %d packages\n",
+		report.Translated, report.Statements, 100*report.Coverage, len(report.Packages))
+}
@@ -0,0 +1,53 @@
+// --- golden/cmd/golden/check_test.go ---
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckCountsUntranslatedStatements(t *testing.T) {
+	dir := t.TempDir()
+	src := `package main
+
+import "fmt"
+
+func main() {
+	var x any = 1
+	switch x.(type) {
+	case int:
+		fmt.Println("int")
+	}
+	fmt.Println("done")
+}
+`
+	files := map[string]string{
+		"main.go":      src,
+		"main_test.go": "package main\n\nimport \"testing\"\n\nfunc TestX(t *testing.T) {}\n",
+	}
+	for name, code := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report := &checkReport{Constructs: make(map[string]map[string]int)}
+	pkgs := checkTarget(dir, report)
+	if len(pkgs) != 1 || pkgs[0].Error != "" {
+		t.Fatalf("packages = %+v", pkgs)
+	}
+	pr := pkgs[0]
+	if len(pr.Files) != 1 {
+		t.Fatalf("checked %d files, want main.go alone", len(pr.Files))
+	}
+	// var, the switch, its x.(type), the case clause, the two Printlns:
+	// only the switch, the innermost statement around the error, fails
+	if pr.Statements != 6 || pr.Translated != 5 {
+		t.Errorf("statements %d, translated %d; want 6 and 5", pr.Statements, pr.Translated)
+	}
+	if report.Constructs["statement"]["type switch"] != 1 {
+		t.Errorf("constructs = %v", report.Constructs)
+	}
+}
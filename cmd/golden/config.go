@@ -0,0 +1,267 @@
+// --- golden/cmd/golden/config.go ---
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Config is a golden.toml or golden.json project file: the defaults for
+// every flag, plus what flags can't express. Flags given on the command
+// line win over it.
+//
+//	out       = "build"
+//	target    = "linux_arm64"
+//	odin      = "/opt/odin/odin"
+//
+//	[runtime]
+//	mem       = "strict"
+//	workers   = 4
+//
+//	[packages]            # Go import path → Odin package standing in for it
+//	"github.com/acme/vec" = "shared:vec"
+//
+//	[collections]         # passed to odin build as -collection:name=dir
+//	shared    = "../odin-shared"
+//
+// golden.json has the same shape, with runtime, packages and collections
+// as nested objects. Relative paths are relative to the file.
+type Config struct {
+	Out      string `json:"out"`
+	Target   string `json:"target"`
+	Odin     string `json:"odin"`
+	Verbose  bool   `json:"verbose"`
+	EmitOnly bool   `json:"emit_only"`
+
+	Runtime struct {
+		Mem           string `json:"mem"`
+		Workers       int    `json:"workers"`
+		Race          bool   `json:"race"`
+		Deterministic bool   `json:"deterministic"`
+		Seed          uint64 `json:"seed"`
+	} `json:"runtime"`
+
+	Packages    map[string]string `json:"packages"`
+	Collections map[string]string `json:"collections"`
+
+	path string // where it was read from; "" when there is no file
+}
+
+var configNames = []string{"golden.toml", "golden.json"}
+
+// findConfig looks for a project file next to inputPath, then in each
+// parent directory.
+func findConfig(inputPath string) (string, bool) {
+	dir, err := filepath.Abs(inputPath)
+	if err != nil {
+		return "", false
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	for {
+		for _, name := range configNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// loadConfig reads the project file at path.
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{path: path}
+	if strings.HasSuffix(path, ".json") {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	} else if err := parseTOML(data, cfg); err != nil {
+		return nil, fmt.Errorf("%s:%v", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	cfg.Out = resolvePath(dir, cfg.Out)
+	if strings.ContainsRune(cfg.Odin, filepath.Separator) {
+		cfg.Odin = resolvePath(dir, cfg.Odin)
+	}
+	for name, p := range cfg.Collections {
+		cfg.Collections[name] = resolvePath(dir, p)
+	}
+	return cfg, nil
+}
+
+func resolvePath(dir, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// parseTOML reads the TOML subset golden.toml needs: [tables] holding
+// key = value pairs, where a key may be quoted and a value is a string,
+// an integer or a boolean.
+func parseTOML(data []byte, cfg *Config) error {
+	table := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("%d: malformed table header %q", n, line)
+			}
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("%d: expected key = value, got %q", n, line)
+		}
+		key = strings.TrimSpace(key)
+		if unquoted, err := strconv.Unquote(key); err == nil {
+			key = unquoted
+		}
+		if err := cfg.set(table, key, strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("%d: %v", n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// stripComment drops a # comment that isn't inside a string.
+func stripComment(line string) string {
+	inString := false
+	for i, r := range line {
+		switch {
+		case r == '"' && (i == 0 || line[i-1] != '\\'):
+			inString = !inString
+		case r == '#' && !inString:
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// set assigns one TOML key.
+func (c *Config) set(table, key, raw string) error {
+	str := func() (string, error) {
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", fmt.Errorf("%s: want a quoted string, got %s", key, raw)
+		}
+		return s, nil
+	}
+	boolean := func(dst *bool) error {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s: want true or false, got %s", key, raw)
+		}
+		*dst = v
+		return nil
+	}
+	var err error
+	switch table + "." + key {
+	case ".out":
+		c.Out, err = str()
+	case ".target":
+		c.Target, err = str()
+	case ".odin":
+		c.Odin, err = str()
+	case ".verbose":
+		err = boolean(&c.Verbose)
+	case ".emit_only":
+		err = boolean(&c.EmitOnly)
+	case "runtime.mem":
+		c.Runtime.Mem, err = str()
+	case "runtime.workers":
+		c.Runtime.Workers, err = strconv.Atoi(raw)
+	case "runtime.race":
+		err = boolean(&c.Runtime.Race)
+	case "runtime.deterministic":
+		err = boolean(&c.Runtime.Deterministic)
+	case "runtime.seed":
+		c.Runtime.Seed, err = strconv.ParseUint(raw, 10, 64)
+	default:
+		switch table {
+		case "packages", "collections":
+			var v string
+			if v, err = str(); err != nil {
+				return err
+			}
+			m := &c.Packages
+			if table == "collections" {
+				m = &c.Collections
+			}
+			if *m == nil {
+				*m = make(map[string]string)
+			}
+			(*m)[key] = v
+			return nil
+		}
+		if table != "" {
+			key = table + "." + key
+		}
+		return fmt.Errorf("unknown setting %s", key)
+	}
+	return err
+}
+
+// apply sets every flag the command line didn't from the file.
+func (c *Config) apply(fs *flag.FlagSet) error {
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	values := map[string]string{
+		"out":    c.Out,
+		"target": c.Target,
+		"odin":   c.Odin,
+		"mem":    c.Runtime.Mem,
+	}
+	if c.Runtime.Workers != 0 {
+		values["workers"] = strconv.Itoa(c.Runtime.Workers)
+	}
+	if c.Runtime.Seed != 0 {
+		values["seed"] = strconv.FormatUint(c.Runtime.Seed, 10)
+	}
+	for name, on := range map[string]bool{
+		"v":             c.Verbose,
+		"emit-only":     c.EmitOnly,
+		"race":          c.Runtime.Race,
+		"deterministic": c.Runtime.Deterministic,
+	} {
+		if on {
+			values[name] = "true"
+		}
+	}
+
+	for name, v := range values {
+		// Settings the current command has no flag for don't apply to it
+		if v == "" || given[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, v); err != nil {
+			return fmt.Errorf("%s: %s: %v", c.path, name, err)
+		}
+	}
+	return nil
+}
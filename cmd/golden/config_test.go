@@ -0,0 +1,68 @@
+// --- golden/cmd/golden/config_test.go ---
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigFillsUnsetFlags(t *testing.T) {
+	dir := t.TempDir()
+	toml := `out = "build"   # relative to the file
+odin = "odin"
+
+[runtime]
+mem = "strict"
+workers = 4
+race = true
+
+[packages]
+"github.com/acme/vec" = "shared:vec"
+
+[collections]
+shared = "../odin-shared"
+`
+	if err := os.WriteFile(filepath.Join(dir, "golden.toml"), []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(dir, "cmd", "app")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path, ok := findConfig(src)
+	if !ok || path != filepath.Join(dir, "golden.toml") {
+		t.Fatalf("findConfig = %s, %v", path, ok)
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Packages["github.com/acme/vec"] != "shared:vec" {
+		t.Errorf("packages = %v", cfg.Packages)
+	}
+	if want := filepath.Join(filepath.Dir(dir), "odin-shared"); cfg.Collections["shared"] != want {
+		t.Errorf("collection shared = %s, want %s", cfg.Collections["shared"], want)
+	}
+
+	fs := flag.NewFlagSet("golden build", flag.ContinueOnError)
+	out := fs.String("out", "out", "")
+	mem := fs.String("mem", "debug", "")
+	workers := fs.Int("workers", 0, "")
+	race := fs.Bool("race", false, "")
+	if err := fs.Parse([]string{"-mem", "release", src}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.apply(fs); err != nil {
+		t.Fatal(err)
+	}
+	if *out != filepath.Join(dir, "build") || *workers != 4 || !*race {
+		t.Errorf("out = %s, workers = %d, race = %v: not taken from the file", *out, *workers, *race)
+	}
+	if *mem != "release" {
+		t.Errorf("mem = %s, want the command line's release", *mem)
+	}
+}
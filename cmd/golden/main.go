@@ -3,137 +3,357 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"io"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
+	"time"
 
-	"github.com/v4rm4n/golden/internal/transpiler"
+	goldenrt "github.com/v4rm4n/golden/runtime"
+	"github.com/v4rm4n/golden/transpiler"
 )
 
+const usage = `Usage:
+  golden [transpile] [flags] <input.go | input_dir> [output-dir]
+  golden build [flags] [-o exe] <input.go | input_dir>
+  golden run [flags] <input.go | input_dir> [args...]
+  golden watch [flags] [-run] <input.go | input_dir> [args...]
+  golden check [-json] [-v] [dir | dir/... | file.go]...
+
+Defaults come from golden.toml or golden.json when there is one; flags
+override it.
+
+transpile writes main.odin and the runtime packages it imports; build
+also compiles them with odin build; run builds, then executes the
+program with args and exits with its status. watch transpiles again
+whenever a .go file changes, and with -run restarts the program. check
+reports how much of existing packages golden can translate, without
+writing anything.`
+
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: golden <input.go | input_dir> [output-dir]")
+	args := os.Args[1:]
+	cmd := "transpile"
+	if len(args) > 0 {
+		switch args[0] {
+		case "transpile", "build", "run", "watch":
+			cmd, args = args[0], args[1:]
+		case "check":
+			checkMain(args[1:])
+			return
+		case "help", "-h", "-help", "--help":
+			fmt.Fprintln(os.Stderr, usage)
+			return
+		}
+	}
+
+	fs := flag.NewFlagSet("golden "+cmd, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, usage)
+		fmt.Fprintln(os.Stderr, "\nFlags:")
+		fs.PrintDefaults()
+	}
+	configFlag := fs.String("config", "", "project file (default: golden.toml or golden.json beside the input or in a parent directory)")
+	outFlag := fs.String("out", "out", "directory for the generated Odin package")
+	memFlag := fs.String("mem", "debug", "memory checking in the generated program: debug, release or strict")
+	workersFlag := fs.Int("workers", 0, "task pool size of the generated program (0: one per CPU core)")
+	raceFlag := fs.Bool("race", false, "instrument the generated program with the data race detector")
+	detFlag := fs.Bool("deterministic", false, "run goroutines one at a time on a seeded cooperative scheduler")
+	seedFlag := fs.Uint64("seed", 1, "interleaving seed for -deterministic (GOLDEN_SEED overrides it at run time)")
+	verboseFlag := fs.Bool("v", false, "print each step, including the odin command line")
+	keepGoingFlag := fs.Bool("keep-going", false, "write (and build) the output even when some of the input couldn't be translated")
+	var exeFlag, odinFlag, targetFlag *string
+	var emitOnlyFlag, runFlag *bool
+	var intervalFlag *time.Duration
+	if cmd != "transpile" {
+		odinFlag = fs.String("odin", "", "Odin compiler to use (default $ODIN, then odin on PATH)")
+		targetFlag = fs.String("target", "", "Odin target to build for, e.g. linux_arm64 (default: the host)")
+	}
+	switch cmd {
+	case "build":
+		exeFlag = fs.String("o", "", "output executable (default <out>/<input name>)")
+		emitOnlyFlag = fs.Bool("emit-only", false, "write the Odin package, but don't compile it")
+	case "run":
+		emitOnlyFlag = fs.Bool("emit-only", false, "write the Odin package, but don't compile it")
+	case "watch":
+		runFlag = fs.Bool("run", false, "build and (re)start the program after every successful transpile")
+		intervalFlag = fs.Duration("interval", 500*time.Millisecond, "how often to poll the sources")
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	inputPath := fs.Arg(0)
+
+	// Settings from the project file fill in the flags left unset
+	cfg := &Config{}
+	configPath, found := *configFlag, *configFlag != ""
+	if !found {
+		configPath, found = findConfig(inputPath)
+	}
+	if found {
+		var err error
+		if cfg, err = loadConfig(configPath); err != nil {
+			log.Fatal(err)
+		}
+		if err := cfg.apply(fs); err != nil {
+			log.Fatal(err)
+		}
+		if *verboseFlag {
+			fmt.Printf("Using project file: %s\n", configPath)
+		}
+	}
+
+	memMode, err := transpiler.ParseMemoryMode(*memFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := transpiler.Options{
+		Memory:        memMode,
+		Race:          *raceFlag,
+		Deterministic: *detFlag,
+		Seed:          *seedFlag,
+		Workers:       *workersFlag,
+		Packages:      cfg.Packages,
 	}
+	outDir := *outFlag
 
-	inputPath := os.Args[1]
-	outDir := "out"
-	if len(os.Args) >= 3 {
-		outDir = os.Args[2]
+	if cmd == "watch" {
+		w := &watcher{
+			input:     inputPath,
+			outDir:    outDir,
+			opts:      opts,
+			verbose:   *verboseFlag,
+			keepGoing: *keepGoingFlag,
+			interval:  *intervalFlag,
+			args:      fs.Args()[1:],
+		}
+		if *runFlag {
+			odin, err := findOdin(*odinFlag)
+			if err != nil {
+				log.Fatal(err)
+			}
+			w.build = &odinBuild{
+				odin:        odin,
+				pkgDir:      outDir,
+				exe:         defaultExe(inputPath, outDir, *targetFlag),
+				target:      *targetFlag,
+				collections: cfg.Collections,
+				verbose:     *verboseFlag,
+			}
+		}
+		w.loop()
+		return
+	}
+
+	if cmd == "transpile" {
+		if fs.NArg() >= 2 {
+			outDir = fs.Arg(1)
+		}
+		untranslated := checkTranspile(transpile(inputPath, outDir, opts, true, *keepGoingFlag))
+		if untranslated {
+			os.Exit(1)
+		}
+		fmt.Printf("\nTo compile:\n  cd %s && odin run .\n", outDir)
+		return
+	}
+
+	untranslated := checkTranspile(transpile(inputPath, outDir, opts, cmd == "build" || *verboseFlag, *keepGoingFlag))
+	if *emitOnlyFlag {
+		if untranslated {
+			os.Exit(1)
+		}
+		return
+	}
+	odin, err := findOdin(*odinFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b := odinBuild{
+		odin:        odin,
+		pkgDir:      outDir,
+		exe:         defaultExe(inputPath, outDir, *targetFlag),
+		target:      *targetFlag,
+		collections: cfg.Collections,
+		verbose:     *verboseFlag,
+	}
+	if exeFlag != nil && *exeFlag != "" {
+		b.exe = *exeFlag
 	}
+	if err := b.run(); err != nil {
+		log.Fatal(err)
+	}
+	if cmd == "build" {
+		fmt.Printf("✓ Built      → %s\n", b.exe)
+		if untranslated {
+			os.Exit(1)
+		}
+		return
+	}
+	os.Exit(runProgram(b.exe, fs.Args()[1:]))
+}
 
+// transpile turns the Go file or package directory at inputPath into
+// main.odin in outDir, next to the runtime packages it imports. verbose
+// prints what was read and written. Diagnostics go to stderr; errors
+// among them stop it before anything is written, unless keepGoing. When
+// the cache says outDir is already up to date, nothing is touched.
+func transpile(inputPath, outDir string, opts transpiler.Options, verbose, keepGoing bool) error {
 	// 1. Determine if input is a file or a directory
 	info, err := os.Stat(inputPath)
 	if err != nil {
-		log.Fatalf("Could not read input path: %v", err)
+		return fmt.Errorf("could not read input path: %v", err)
+	}
+
+	sources, err := sourceFiles(inputPath)
+	if err != nil {
+		return fmt.Errorf("could not read input path: %v", err)
+	}
+	key, err := cacheKey(sources, opts)
+	if err != nil {
+		return fmt.Errorf("could not read input: %v", err)
+	}
+	outFile := filepath.Join(outDir, "main.odin")
+	cache := loadCache(outDir)
+	if cache.fresh(outDir, key) {
+		for _, d := range cache.Diagnostics {
+			fmt.Fprintln(os.Stderr, d)
+		}
+		if cache.Untranslated {
+			if !keepGoing {
+				return errCouldNotTranslate
+			}
+			return errUntranslated
+		}
+		if verbose {
+			fmt.Printf("✓ Up to date → %s\n", outFile)
+		}
+		return nil
 	}
 
 	fset := token.NewFileSet()
-	var mergedDecls []ast.Decl
+	pkg := &transpiler.Package{Path: inputPath, Fset: fset}
 
 	if info.IsDir() {
 		// 2A. Directory Mode: Parse all files in the package
-		pkgs, err := parser.ParseDir(fset, inputPath, nil, parser.ParseComments)
+		notTest := func(fi fs.FileInfo) bool { return !isTestFile(fi.Name()) }
+		pkgs, err := parser.ParseDir(fset, inputPath, notTest, parser.ParseComments)
 		if err != nil {
-			log.Fatalf("Failed to parse directory: %v", err)
+			return fmt.Errorf("failed to parse directory: %v", err)
 		}
 
 		// Look for the "main" package
 		mainPkg, ok := pkgs["main"]
 		if !ok {
-			log.Fatalf("No 'main' package found in directory %s", inputPath)
+			return fmt.Errorf("no 'main' package found in directory %s", inputPath)
 		}
-
-		// Merge all declarations from all files in the package
 		for _, fileNode := range mainPkg.Files {
-			mergedDecls = append(mergedDecls, fileNode.Decls...)
+			pkg.Files = append(pkg.Files, fileNode)
+		}
+		if verbose {
+			fmt.Printf("Parsed %d files from directory: %s\n", len(mainPkg.Files), inputPath)
 		}
-		fmt.Printf("Parsed %d files from directory: %s\n", len(mainPkg.Files), inputPath)
 
 	} else {
 		// 2B. File Mode: Parse just the single file
 		node, err := parser.ParseFile(fset, inputPath, nil, parser.ParseComments)
 		if err != nil {
-			log.Fatalf("Failed to parse file: %v", err)
+			return fmt.Errorf("failed to parse file: %v", err)
+		}
+		pkg.Files = []*ast.File{node}
+		if verbose {
+			fmt.Printf("Parsed single file: %s\n", inputPath)
 		}
-		mergedDecls = node.Decls
-		fmt.Printf("Parsed single file: %s\n", inputPath)
 	}
 
-	// 3. Create a synthetic AST File containing all merged declarations
-	mergedFile := &ast.File{
-		Name:  &ast.Ident{Name: "main"},
-		Decls: mergedDecls,
+	// 3. Transpile; the files are merged in name order
+	result, err := transpiler.New(transpiler.Config{Options: opts}).Transpile(context.Background(), []*transpiler.Package{pkg})
+	if err != nil {
+		return err
 	}
-
-	// 4. Setup output directories
-	goldenDir := filepath.Join(outDir, "golden")
-	if err := os.MkdirAll(goldenDir, 0755); err != nil {
-		log.Fatal("Could not create output dir:", err)
+	odinOutput, diags := result.Files[0].Code, result.Diagnostics
+	for _, d := range diags {
+		fmt.Fprintln(os.Stderr, d)
+	}
+	untranslated := transpiler.HasErrors(diags)
+	if untranslated && !keepGoing {
+		return errCouldNotTranslate
 	}
 
-	// 5. Transpile
-	odinOutput := transpiler.Process(mergedFile)
-
-	// Optional Polish: Clean up duplicate imports if files had them
-	odinOutput = cleanDuplicateImports(odinOutput)
-
-	outFile := filepath.Join(outDir, "main.odin")
-	if err := os.WriteFile(outFile, []byte(odinOutput), 0644); err != nil {
-		log.Fatal("Could not write output:", err)
+	// 4. Setup output directory
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("could not create output dir: %v", err)
 	}
 
-	// 6. Copy Runtime
-	runtimeSrc := filepath.Join("runtime", "golden.odin")
-	runtimeDst := filepath.Join(goldenDir, "golden.odin")
-	if err := copyFile(runtimeSrc, runtimeDst); err != nil {
-		log.Printf("Warning: could not copy runtime: %v", err)
+	if _, err := writeIfChanged(outFile, []byte(odinOutput)); err != nil {
+		return fmt.Errorf("could not write output: %v", err)
 	}
 
-	// 7. Done
-	fmt.Printf("✓ Transpiled → %s\n", outFile)
-	fmt.Printf("✓ Runtime    → %s\n", runtimeDst)
-	fmt.Printf("\nTo compile:\n  cd %s && odin run .\n", outDir)
-}
+	// 5. Write the runtime packages it imports
+	runtimeFiles, err := goldenrt.Write(outDir, odinOutput)
+	if err != nil {
+		return err
+	}
+	if err := cache.record(outDir, key, append([]string{outFile}, runtimeFiles...), diags); err != nil {
+		return fmt.Errorf("could not write cache: %v", err)
+	}
 
-// cleanDuplicateImports removes duplicate import statements that might occur
-// when merging multiple Go files that all import "fmt"
-func cleanDuplicateImports(code string) string {
-	lines := strings.Split(code, "\n")
-	seen := make(map[string]bool)
-	var out []string
-
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "import ") {
-			if seen[trimmed] {
-				continue
-			}
-			seen[trimmed] = true
+	// 6. Done
+	if verbose {
+		fmt.Printf("✓ Transpiled → %s\n", outFile)
+		for _, f := range runtimeFiles {
+			fmt.Printf("✓ Runtime    → %s\n", f)
 		}
-		out = append(out, line)
 	}
-	return strings.Join(out, "\n")
+	if untranslated {
+		return errUntranslated
+	}
+	return nil
 }
 
-func copyFile(src, dst string) error {
-	in, err := os.Open(src)
+var errCouldNotTranslate = errors.New("could not translate the input (use -keep-going to write the output anyway)")
+
+// errUntranslated is transpile's result when -keep-going wrote output
+// despite errors: the command carries on, but exits 1 in the end.
+var errUntranslated = errors.New("output written with untranslated code")
+
+// checkTranspile exits on a transpile failure and reports whether the
+// output has untranslated code in it.
+func checkTranspile(err error) bool {
+	if err == errUntranslated {
+		return true
+	}
 	if err != nil {
-		return err
+		log.Fatal(err)
 	}
-	defer in.Close()
+	return false
+}
 
-	out, err := os.Create(dst)
+// writeFileAtomic replaces path in one step, so a build started meanwhile
+// (golden watch, an editor's language server) never reads half a file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
 	if err != nil {
 		return err
 	}
-	defer out.Close()
-
-	_, err = io.Copy(out, in)
-	return err
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
@@ -0,0 +1,137 @@
+// --- golden/cmd/golden/watch.go ---
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/v4rm4n/golden/transpiler"
+)
+
+// watcher is `golden watch`: it polls the input's .go files and
+// transpiles again whenever one changes. With build set, each good
+// transpile is also compiled and the program restarted.
+type watcher struct {
+	input     string
+	outDir    string
+	opts      transpiler.Options
+	verbose   bool
+	keepGoing bool
+	interval  time.Duration
+	build     *odinBuild // nil: transpile only
+	args      []string   // for the program
+
+	prog *exec.Cmd     // running program, if any
+	done chan struct{} // closed when prog exits
+}
+
+func (w *watcher) loop() {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	fmt.Printf("Watching %s (Ctrl-C to stop)\n", w.input)
+	last := ""
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		sig, err := sourceSignature(w.input)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		} else if sig != last {
+			last = sig
+			w.cycle()
+		}
+		select {
+		case <-ticker.C:
+		case <-interrupt:
+			w.stop()
+			return
+		}
+	}
+}
+
+// cycle transpiles once and, in -run mode, rebuilds and restarts.
+func (w *watcher) cycle() {
+	fmt.Printf("\n[%s] transpiling %s\n", time.Now().Format("15:04:05"), w.input)
+	err := transpile(w.input, w.outDir, w.opts, w.verbose, w.keepGoing)
+	if err != nil && !errors.Is(err, errUntranslated) {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	if w.build == nil {
+		if !w.verbose { // transpile said so already
+			fmt.Printf("✓ Transpiled → %s\n", filepath.Join(w.outDir, "main.odin"))
+		}
+		return
+	}
+	if err := w.build.run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	w.stop()
+	w.start()
+}
+
+func (w *watcher) start() {
+	path, err := filepath.Abs(w.build.exe)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	cmd := exec.Command(path, w.args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "golden watch: %v\n", err)
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		err := cmd.Wait()
+		var exit *exec.ExitError
+		switch {
+		case errors.As(err, &exit) && exit.ExitCode() >= 0:
+			fmt.Printf("[%s] program exited with status %d\n", time.Now().Format("15:04:05"), exit.ExitCode())
+		case err == nil:
+			fmt.Printf("[%s] program exited\n", time.Now().Format("15:04:05"))
+		}
+		close(done)
+	}()
+	w.prog, w.done = cmd, done
+}
+
+// stop kills the program the last cycle started, if it still runs.
+func (w *watcher) stop() {
+	if w.prog == nil {
+		return
+	}
+	w.prog.Process.Kill()
+	<-w.done
+	w.prog, w.done = nil, nil
+}
+
+// sourceSignature fingerprints the files transpile reads for input (see
+// sourceFiles) by name, size and modification time.
+func sourceSignature(input string) (string, error) {
+	files, err := sourceFiles(input)
+	if err != nil {
+		return "", err
+	}
+	var sig strings.Builder
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sig, "%s %d %d\n", f, fi.Size(), fi.ModTime().UnixNano())
+	}
+	return sig.String(), nil
+}
@@ -0,0 +1,69 @@
+// --- golden/runtime/embed.go ---
+
+// Package runtime embeds the Odin runtime so the transpiler can write it
+// next to the generated code from wherever it runs.
+package runtime
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+//go:embed *.odin
+var files embed.FS
+
+// Package is one Odin package of the runtime.
+type Package struct {
+	// Import is the path generated code imports it by, relative to the
+	// output directory.
+	Import string
+	Files  []string
+}
+
+// Packages lists the runtime's Odin packages. They share this directory
+// here, but Odin wants one package per directory on disk.
+var Packages = []Package{
+	{Import: "golden", Files: []string{"golden.odin"}},
+	{Import: "golden/runtime", Files: []string{"std_os.odin"}},
+}
+
+var importRe = regexp.MustCompile(`(?m)^\s*import\s+(?:\w+\s+)?"([^"]+)"`)
+
+// Write lays out under outDir the runtime packages the Odin source code
+// imports, and returns their files. Files already up to date are left
+// alone, so their mtimes don't change.
+func Write(outDir, code string) ([]string, error) {
+	imported := make(map[string]bool)
+	for _, m := range importRe.FindAllStringSubmatch(code, -1) {
+		imported[m[1]] = true
+	}
+	var laidOut []string
+	for _, pkg := range Packages {
+		if !imported[pkg.Import] {
+			continue
+		}
+		dir := filepath.Join(outDir, filepath.FromSlash(pkg.Import))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return laidOut, fmt.Errorf("could not create runtime dir: %v", err)
+		}
+		for _, name := range pkg.Files {
+			data, err := files.ReadFile(name)
+			if err != nil {
+				return laidOut, err
+			}
+			dst := filepath.Join(dir, name)
+			laidOut = append(laidOut, dst)
+			if old, err := os.ReadFile(dst); err == nil && bytes.Equal(old, data) {
+				continue
+			}
+			if err := os.WriteFile(dst, data, 0644); err != nil {
+				return laidOut, fmt.Errorf("could not write runtime: %v", err)
+			}
+		}
+	}
+	return laidOut, nil
+}
@@ -0,0 +1,38 @@
+// --- golden/runtime/embed_test.go ---
+
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteOnlyImportedPackages(t *testing.T) {
+	dir := t.TempDir()
+	code := "package main\n\nimport \"core:fmt\"\nimport golden \"golden\"\n"
+	files, err := Write(dir, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, "golden", "golden.odin")
+	if len(files) != 1 || files[0] != want {
+		t.Fatalf("Write = %q, want [%s]", files, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "golden", "runtime")); !os.IsNotExist(err) {
+		t.Error("Write laid out golden/runtime, which the code doesn't import")
+	}
+
+	// An unchanged file keeps its mtime
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(want, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Write(dir, code); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(want); err != nil || !fi.ModTime().Equal(old) {
+		t.Errorf("Write rewrote an up-to-date runtime file")
+	}
+}
@@ -2,46 +2,117 @@
 
 package golden
 
+import "base:runtime"
+import "core:container/queue"
+import "core:slice/heap"
 import "core:fmt"
 import "core:mem"
+import "core:os"
+import "core:strconv"
 import "core:sync"
 import "core:thread"
 import "core:strings"
+import "core:time"
 
 // ═══════════════════════════════════════════════════════════════════
 // ARC — Automatic Reference Counting
 // ═══════════════════════════════════════════════════════════════════
 
+// Arc_Counts is the control block shared by every Arc and Weak handle
+// to one allocation. It outlives the data while weak handles remain.
+Arc_Counts :: struct {
+    strong:    int,
+    weak:      int,
+    drop:      proc(rawptr),  // releases ARC handle fields before the data is freed
+    allocator: mem.Allocator, // the last release may run on another thread
+}
+
 Arc :: struct($T: typeid) {
     data:      ^T,
-    ref_count: ^int, // Renamed from 'count' to 'ref_count'
+    ref_count: ^Arc_Counts,
 }
 
-make_arc :: proc(value: $T) -> Arc(T) {
-    d := new(T)
+make_arc :: proc(value: $T, drop: proc(rawptr) = nil, loc := #caller_location) -> Arc(T) {
+    d := new(T, loc = loc)
     d^ = value
-    c := new(int)
-    c^ = 1
+    c := new(Arc_Counts, loc = loc)
+    // The strong handles together hold one weak reference (see _counts_release)
+    c^ = Arc_Counts{strong = 1, weak = 1, drop = drop, allocator = context.allocator}
     return Arc(T){data = d, ref_count = c}
 }
 
+// Strong counts are atomic: goroutines and contexts share handles
+// across worker threads.
 retain :: proc(a: Arc($T)) -> Arc(T) {
     if a.ref_count != nil {
-        a.ref_count^ += 1
+        sync.atomic_add(&a.ref_count.strong, 1)
     }
     return a
 }
 
+// _arc_try_retain takes a strong reference only while one still exists,
+// for runtime structures that point at an Arc without owning it.
+_arc_try_retain :: proc(c: ^Arc_Counts) -> bool {
+    for {
+        n := sync.atomic_load(&c.strong)
+        if n <= 0 do return false
+        if _, ok := sync.atomic_compare_exchange_strong(&c.strong, n, n + 1); ok do return true
+    }
+}
+
+// arc_move transfers ownership out of a handle without touching the count:
+// the handle is emptied, so its pending arc_release becomes a no-op.
+arc_move :: proc(a: ^Arc($T)) -> Arc(T) {
+    moved := a^
+    a^ = {}
+    return moved
+}
+
+// debug_memory is switched on by the generated main in debug/strict mode:
+// freed ARC data is poisoned and refcount underflow traps.
+debug_memory: bool
+
+ARC_POISON       :: 0xDD
+ARC_POISON_COUNT :: min(int)
+
 // This is what the transpiler calls!
-arc_release :: proc(arc: ^Arc($T)) {
+arc_release :: proc(arc: ^Arc($T), loc := #caller_location) {
     if arc.ref_count == nil do return
 
-    arc.ref_count^ -= 1
-    if arc.ref_count^ == 0 {
-        if arc.data != nil do free(arc.data)
-        free(arc.ref_count)
-        arc.data = nil
-        arc.ref_count = nil
+    counts := arc.ref_count
+    if debug_memory {
+        // A poisoned or non-positive count means another handle already
+        // dropped the last reference — this release is a double free.
+        assert(counts.strong > 0, "ARC refcount underflow (released after last reference)", loc)
+    }
+
+    if sync.atomic_sub(&counts.strong, 1) == 1 {
+        if arc.data != nil {
+            if counts.drop != nil do counts.drop(arc.data)
+            if race_enabled do _race_forget(arc.data, size_of(T))
+            if debug_memory do mem.set(arc.data, ARC_POISON, size_of(T))
+            free(arc.data, counts.allocator, loc)
+        }
+        _counts_release(counts, loc)
+    }
+    arc.data = nil
+    arc.ref_count = nil
+}
+
+// arc_store implements `holder.field = target` for a strong field: next
+// is already owned, the old target is released.
+arc_store :: proc(field: ^Arc($T), next: Arc(T)) {
+    arc_release(field)
+    field^ = next
+}
+
+// _counts_release drops one weak reference. The strong handles share a
+// single one, released with the data, so the counts block is freed
+// exactly once, by whichever side drops the last.
+_counts_release :: proc(c: ^Arc_Counts, loc := #caller_location) {
+    if sync.atomic_sub(&c.weak, 1) == 1 {
+        if debug_memory do c.strong = ARC_POISON_COUNT
+        free(c, c.allocator, loc)
     }
 }
 
@@ -51,6 +122,50 @@ release :: proc(a: Arc($T)) {
     arc_release(&local_a)
 }
 
+// ═══════════════════════════════════════════════════════════════════
+// WEAK — Non-owning ARC handles (//golden:weak fields)
+// ═══════════════════════════════════════════════════════════════════
+//
+// Back-pointers (parent links, observer lists) stored as Weak don't keep
+// their target alive, so parent/child graphs are freed instead of leaking.
+
+Weak :: struct($T: typeid) {
+    data:   ^T,
+    counts: ^Arc_Counts,
+}
+
+weak_make :: proc(a: Arc($T)) -> Weak(T) {
+    if a.ref_count == nil do return {}
+    sync.atomic_add(&a.ref_count.weak, 1)
+    return Weak(T){data = a.data, counts = a.ref_count}
+}
+
+// weak_assign implements `holder.field = target` for a weak field.
+weak_assign :: proc(w: ^Weak($T), a: Arc(T)) {
+    next := weak_make(a)
+    weak_release(w)
+    w^ = next
+}
+
+// weak_get peeks at the target without owning it; nil once it is freed.
+weak_get :: proc(w: Weak($T)) -> ^T {
+    if w.counts == nil || sync.atomic_load(&w.counts.strong) <= 0 do return nil
+    return w.data
+}
+
+// weak_upgrade turns a weak handle back into an owning one if the
+// target is still alive. The caller must arc_release the result.
+weak_upgrade :: proc(w: Weak($T)) -> (Arc(T), bool) {
+    if w.counts == nil || !_arc_try_retain(w.counts) do return {}, false
+    return Arc(T){data = w.data, ref_count = w.counts}, true
+}
+
+weak_release :: proc(w: ^Weak($T)) {
+    if w.counts == nil do return
+    _counts_release(w.counts)
+    w^ = {}
+}
+
 // ═══════════════════════════════════════════════════════════════════
 // ARENA — Frame Allocator
 // ═══════════════════════════════════════════════════════════════════
@@ -58,142 +173,1037 @@ release :: proc(a: Arc($T)) {
 FRAME_SIZE :: 1024 * 64
 
 Frame :: struct {
-    buf:    [FRAME_SIZE]byte,
-    offset: int,
+    buf:      [FRAME_SIZE]byte,
+    offset:   int,
+    backing:  mem.Allocator,   // overflow allocations spill here
+    overflow: [dynamic]rawptr, // ...and are freed by frame_end
 }
 
-frame_begin :: proc() -> Frame { return Frame{} }
+frame_begin :: proc() -> Frame { return Frame{backing = context.allocator} }
 
 frame_end :: proc(f: ^Frame) {
-    f.offset = 0
+    for p in f.overflow {
+        mem.free(p, f.backing)
+    }
+    delete(f.overflow)
+    f.overflow = nil
+    f.offset   = 0
 }
 
-frame_new :: proc(value: $T, f: ^Frame) -> ^T {
-    size    := size_of(T)
-    align   := align_of(T)
-    aligned := (f.offset + align - 1) & ~(align - 1)
+// _frame_alloc bumps the frame offset, spilling to the backing allocator
+// once the inline buffer is exhausted.
+_frame_alloc :: proc(f: ^Frame, size, alignment: int) -> ([]byte, mem.Allocator_Error) {
+    align   := max(alignment, 1)
+    base    := uintptr(&f.buf[0])
+    start   := mem.align_forward_uintptr(base + uintptr(f.offset), uintptr(align))
+    aligned := int(start - base)
     if aligned + size > FRAME_SIZE {
-        d := new(T)
-        d^ = value
-        return d
+        data, err := mem.alloc_bytes(size, align, f.backing)
+        if err == .None {
+            if cap(f.overflow) == 0 {
+                f.overflow = make([dynamic]rawptr, 0, 4, f.backing)
+            }
+            append(&f.overflow, raw_data(data))
+        }
+        return data, err
     }
-    ptr := cast(^T)&f.buf[aligned]
-    ptr^ = value
     f.offset = aligned + size
+    return f.buf[aligned:][:size], .None
+}
+
+frame_new :: proc(value: $T, f: ^Frame) -> ^T {
+    data, _ := _frame_alloc(f, size_of(T), align_of(T))
+    ptr := cast(^T)raw_data(data)
+    ptr^ = value
     return ptr
 }
 
+// frame_allocator exposes the frame as a mem.Allocator so slices, maps and
+// strings that don't escape can live (and die) with the frame.
+frame_allocator :: proc(f: ^Frame) -> mem.Allocator {
+    return mem.Allocator{procedure = _frame_allocator_proc, data = f}
+}
+
+_frame_allocator_proc :: proc(allocator_data: rawptr, mode: mem.Allocator_Mode,
+                              size, alignment: int,
+                              old_memory: rawptr, old_size: int,
+                              location := #caller_location) -> ([]byte, mem.Allocator_Error) {
+    f := cast(^Frame)allocator_data
+    switch mode {
+    case .Alloc, .Alloc_Non_Zeroed:
+        return _frame_alloc(f, size, alignment)
+    case .Resize, .Resize_Non_Zeroed:
+        data, err := _frame_alloc(f, size, alignment)
+        if err == .None && old_memory != nil {
+            mem.copy(raw_data(data), old_memory, min(old_size, size))
+        }
+        return data, err
+    case .Free:
+        // Individual frees are no-ops — frame_end releases everything.
+    case .Free_All:
+        frame_end(f)
+    case .Query_Features, .Query_Info:
+        return nil, .Mode_Not_Implemented
+    }
+    return nil, .None
+}
+
 frame_init :: proc(ptr: ^$T, value: T) { ptr^ = value }
 
+// slice_of builds a growable slice from a literal — the target of Go's []T{...}.
+slice_of :: proc(elems: []$T, allocator := context.allocator, loc := #caller_location) -> [dynamic]T {
+    s := make([dynamic]T, 0, len(elems), allocator, loc)
+    append(&s, ..elems)
+    return s
+}
+
+// take moves an owned slice, map or string out of its variable, which is
+// left empty so its deferred delete frees nothing — the target of returns
+// and stores that hand the value on.
+take :: proc(p: ^$T) -> T {
+    v := p^
+    mem.zero_item(p)
+    return v
+}
+
+// replace_owned frees a variable's owned value once its successor, which
+// may have been built from it, is ready: Go's `s = s + x`.
+replace_owned :: proc(p: ^$T, next: T) {
+    delete(p^)
+    p^ = next
+}
+
 // ═══════════════════════════════════════════════════════════════════
-// TASK POOL — Goroutine Scheduler
+// TASK POOL — Work-Stealing Goroutine Scheduler
 // ═══════════════════════════════════════════════════════════════════
-
-MAX_TASKS   :: 1024
-MAX_THREADS :: 32
+//
+// Every worker owns a deque: it pushes and pops its own tasks at the back
+// (LIFO, cache friendly) while idle workers steal from the front (FIFO).
+// Tasks spawned from outside the pool land in a shared injection queue.
+// All queues grow on demand, so spawning never blocks the caller.
+//
+// Goroutines that block (channels, WaitGroup, Mutex, Sleep) would pin
+// their worker thread. Blocking runtime calls are bracketed with
+// blocking_begin/blocking_end; whenever fewer than `count` workers are
+// left runnable, a compensating thread joins the pool so queued tasks
+// keep running.
 
 Task :: struct {
-    fn:   proc(rawptr),
-    data: rawptr,
+    fn:     proc(rawptr),
+    data:   rawptr,
+    origin: string,          // Go position of the spawning go statement
+    ctx:    runtime.Context, // the spawner's, so allocations stay tracked
+    race:   Vector_Clock,    // the spawner's clock at the go statement (-race)
 }
 
-TaskQueue :: struct {
-    tasks:     [MAX_TASKS]Task,
-    head:      int,
-    tail:      int,
-    mu:        sync.Mutex,   // zero-init, no init call needed
-    not_empty: sync.Cond,    // zero-init, no init call needed
-    not_full:  sync.Cond,    // zero-init, no init call needed
-    closed:    bool,
+Deque :: struct {
+    mu:    sync.Mutex, // zero-init, no init call needed
+    tasks: queue.Queue(Task),
 }
 
 Pool :: struct {
-    queue:   TaskQueue,
-    threads: [MAX_THREADS]^thread.Thread,
-    count:   int,
-    running: bool,
+    workers:   []Deque,
+    inject:    Deque,
+    threads:   []^thread.Thread,
+    count:     int,
+    pending:   int,        // queued but not yet started (atomic)
+    blocked:   int,        // pool threads parked in a blocking call (atomic)
+    extra:     [dynamic]^thread.Thread, // compensating threads, guarded by grow_mu
+    grow_mu:   sync.Mutex, // zero-init
+    idle_mu:   sync.Mutex, // zero-init
+    idle_cond: sync.Cond,  // zero-init
+    closed:    bool,
+    running:   bool,
 }
 
 _pool: Pool
 
-queue_push :: proc(q: ^TaskQueue, t: Task) {
-    sync.mutex_lock(&q.mu)
-    for (q.head - q.tail) >= MAX_TASKS && !q.closed {
-        sync.cond_wait(&q.not_full, &q.mu)
+// 1-based index of the worker running on this thread, 0 off the pool,
+// -1 on a compensating thread (it owns no deque).
+@(thread_local) _worker_id: int
+
+deque_push :: proc(d: ^Deque, t: Task) {
+    sync.mutex_lock(&d.mu)
+    queue.push_back(&d.tasks, t)
+    sync.mutex_unlock(&d.mu)
+}
+
+// deque_pop takes the newest task — the owner's end.
+deque_pop :: proc(d: ^Deque) -> (Task, bool) {
+    sync.mutex_lock(&d.mu)
+    defer sync.mutex_unlock(&d.mu)
+    return queue.pop_back_safe(&d.tasks)
+}
+
+// deque_steal takes the oldest task — the thieves' end.
+deque_steal :: proc(d: ^Deque) -> (Task, bool) {
+    sync.mutex_lock(&d.mu)
+    defer sync.mutex_unlock(&d.mu)
+    return queue.pop_front_safe(&d.tasks)
+}
+
+// _submit queues a task on the current worker's deque, or on the
+// injection queue when called from outside the pool.
+_submit :: proc(t: Task) {
+    if _det.enabled {
+        _det_spawn(t)
+        return
     }
-    if !q.closed {
-    // OPTIMIZATION: Bitwise AND for power-of-2 ring buffer
-        q.tasks[q.head & (MAX_TASKS - 1)] = t
-        q.head += 1
-        sync.cond_signal(&q.not_empty)
+    // Count before publishing so an idle worker can't miss it
+    sync.atomic_add(&_pool.pending, 1)
+    if _worker_id > 0 {
+        deque_push(&_pool.workers[_worker_id - 1], t)
+    } else {
+        deque_push(&_pool.inject, t)
     }
-    sync.mutex_unlock(&q.mu)
+    sync.mutex_lock(&_pool.idle_mu)
+    sync.cond_signal(&_pool.idle_cond)
+    sync.mutex_unlock(&_pool.idle_mu)
 }
 
-queue_pop :: proc(q: ^TaskQueue) -> (Task, bool) {
-    sync.mutex_lock(&q.mu)
-    for q.head == q.tail && !q.closed {
-        sync.cond_wait(&q.not_empty, &q.mu)
+// _find_task checks the local deque, then the injection queue, then
+// steals round-robin from the other workers.
+_find_task :: proc(id: int) -> (Task, bool) {
+    if id >= 0 {
+        if t, ok := deque_pop(&_pool.workers[id]); ok do return t, true
     }
-    if q.head == q.tail {
-        sync.mutex_unlock(&q.mu)
-        return {}, false
+    if t, ok := deque_steal(&_pool.inject); ok do return t, true
+    start := max(id, 0)
+    for i in 0..<_pool.count {
+        victim := (start + i) % _pool.count
+        if victim == id do continue
+        if t, ok := deque_steal(&_pool.workers[victim]); ok do return t, true
     }
-    // OPTIMIZATION: Bitwise AND for power-of-2 ring buffer
-    t := q.tasks[q.tail & (MAX_TASKS - 1)]
-    q.tail += 1
-    sync.cond_signal(&q.not_full)
-    sync.mutex_unlock(&q.mu)
-    return t, true
+    return {}, false
 }
 
-queue_close :: proc(q: ^TaskQueue) {
-    sync.mutex_lock(&q.mu)
-    q.closed = true
-    sync.cond_broadcast(&q.not_empty)
-    sync.cond_broadcast(&q.not_full)
-    sync.mutex_unlock(&q.mu)
+_worker_proc :: proc(t: ^thread.Thread) {
+    _worker_id = t.user_index + 1
+    _worker_loop(t.user_index)
 }
 
-_worker_proc :: proc(t: ^thread.Thread) {
+_spare_proc :: proc(t: ^thread.Thread) {
+    _worker_id = -1
+    _worker_loop(-1)
+}
+
+// _worker_loop runs tasks until the pool is closed and drained.
+// id is the worker's own deque, or -1 for a compensating thread.
+_worker_loop :: proc(id: int) {
     for {
-        task, ok := queue_pop(&_pool.queue)
-        if !ok { return }
-        task.fn(task.data)
+        if task, ok := _find_task(id); ok {
+            // Register before leaving pending, so the watchdog never
+            // sees the task in neither place
+            g := Goroutine{origin = task.origin}
+            _g_register(&g)
+            sync.atomic_sub(&_pool.pending, 1)
+            _current_g = &g
+            if race_enabled do _race_start(&g, task.race)
+            _run_task(task)
+            if race_enabled do _race_end(&g)
+            _current_g = nil
+            _g_unregister(&g)
+            continue
+        }
+        sync.mutex_lock(&_pool.idle_mu)
+        for sync.atomic_load(&_pool.pending) <= 0 && !_pool.closed {
+            sync.cond_wait(&_pool.idle_cond, &_pool.idle_mu)
+        }
+        done := _pool.closed && sync.atomic_load(&_pool.pending) <= 0
+        sync.mutex_unlock(&_pool.idle_mu)
+        if done { return }
     }
 }
 
-pool_start :: proc(n: int) {
-    _pool.count   = min(n, MAX_THREADS)
+// pool_start spins up n workers; n <= 0 means one per CPU core.
+pool_start :: proc(n := 0) {
+    count := n
+    if count <= 0 {
+        count = os.processor_core_count()
+    }
+    _pool.count   = max(count, 1)
     _pool.running = true
-    // TaskQueue is zero-initialized — Mutex and Cond need no explicit init
+    _pool.workers = make([]Deque, _pool.count)
+    _pool.threads = make([]^thread.Thread, _pool.count)
+    queue.init(&_pool.inject.tasks)
+    _sched.gs = make([dynamic]^Goroutine, runtime.heap_allocator())
+    _sched.next_id = 1
+    if _det.enabled {
+        _det_start()
+        return
+    }
+    for &d in _pool.workers {
+        queue.init(&d.tasks)
+    }
     for i in 0..<_pool.count {
         t := thread.create(_worker_proc)
+        t.user_index = i
         _pool.threads[i] = t
         thread.start(t)
     }
+    _sched.watchdog = thread.create(_watchdog_proc)
+    thread.start(_sched.watchdog)
 }
 
+// pool_stop lets the workers drain every queued task, then joins them.
 pool_stop :: proc() {
-    queue_close(&_pool.queue)
-    for i in 0..<_pool.count {
-        thread.join(_pool.threads[i])
-        thread.destroy(_pool.threads[i])
+    // main now waits on every remaining goroutine; if they are all
+    // blocked, that is a deadlock rather than a hang at exit
+    blocking_begin(.Exit)
+    if _det.enabled {
+        _det_stop()
+    } else {
+        sync.mutex_lock(&_pool.idle_mu)
+        _pool.closed = true
+        sync.cond_broadcast(&_pool.idle_cond)
+        sync.mutex_unlock(&_pool.idle_mu)
+        for t in _pool.threads {
+            thread.join(t)
+            thread.destroy(t)
+        }
+        // A draining task may still add a compensating thread, so pop one at a time
+        for {
+            sync.mutex_lock(&_pool.grow_mu)
+            t, ok := pop_safe(&_pool.extra)
+            sync.mutex_unlock(&_pool.grow_mu)
+            if !ok do break
+            thread.join(t)
+            thread.destroy(t)
+        }
+        delete(_pool.extra)
+    }
+    blocking_end()
+    _timers_stop()
+    _cancels_destroy()
+
+    if _sched.watchdog != nil {
+        sync.atomic_store(&_sched.stop, true)
+        thread.join(_sched.watchdog)
+        thread.destroy(_sched.watchdog)
+    }
+    delete(_sched.gs)
+    _sched = {}
+    _race_exit()
+    for &d in _pool.workers {
+        queue.destroy(&d.tasks)
+    }
+    queue.destroy(&_pool.inject.tasks)
+    delete(_pool.workers)
+    delete(_pool.threads)
+    _pool = {}
+}
+
+// Upper bound on compensating threads, so a runaway program fails to
+// make progress instead of exhausting the OS thread limit.
+MAX_SPARE_THREADS :: 1024
+
+// blocking_begin records that the current goroutine is about to wait on
+// reason at loc. On a pool thread, when that leaves fewer than `count`
+// runnable threads, a compensating thread is started.
+blocking_begin :: proc(reason: Wait_Reason, loc := #caller_location) {
+    _g_wait(reason, loc)
+    if _worker_id == 0 || !_pool.running do return
+    blocked := sync.atomic_add(&_pool.blocked, 1) + 1
+
+    sync.mutex_lock(&_pool.grow_mu)
+    defer sync.mutex_unlock(&_pool.grow_mu)
+    total := _pool.count + len(_pool.extra)
+    if total - blocked >= _pool.count || len(_pool.extra) >= MAX_SPARE_THREADS do return
+    // Spare threads never retire: once unblocked they park on idle_cond
+    // and count as runnable capacity for the next blocking call.
+    t := thread.create(_spare_proc)
+    append(&_pool.extra, t)
+    thread.start(t)
+}
+
+// blocking_end marks the current goroutine as runnable again.
+blocking_end :: proc() {
+    _g_wait(.None, {})
+    if _worker_id == 0 || !_pool.running do return
+    sync.atomic_sub(&_pool.blocked, 1)
+}
+
+// mutex_lock takes m, telling the scheduler when it has to wait for it.
+mutex_lock :: proc(m: ^sync.Mutex, loc := #caller_location) {
+    _sched_point()
+    if !sync.mutex_try_lock(m) {
+        blocking_begin(.Mutex, loc)
+        if _det.enabled {
+            for !sync.mutex_try_lock(m) do _det_block()
+        } else {
+            sync.mutex_lock(m)
+        }
+        blocking_end()
     }
-    _pool.running = false
+    _race_acquire(m)
+}
+
+mutex_try_lock :: proc(m: ^sync.Mutex) -> bool {
+    _sched_point()
+    if !sync.mutex_try_lock(m) do return false
+    _race_acquire(m)
+    return true
+}
+
+mutex_unlock :: proc(m: ^sync.Mutex) {
+    _sched_point()
+    _race_release(m)
+    sync.mutex_unlock(m)
+}
+
+// sleep parks the current goroutine without starving the pool.
+sleep :: proc(d: time.Duration, loc := #caller_location) {
+    blocking_begin(.Sleep, loc)
+    if _det.enabled {
+        _det_sleep(d)
+    } else {
+        time.sleep(d)
+    }
+    blocking_end()
+}
+
+// _run_task runs a goroutine in its spawner's context: same allocator
+// (main's tracking allocator in debug builds), same assertion handler.
+// The temp allocator stays the worker's own; it is not thread-safe.
+_run_task :: proc(task: Task) {
+    temp := context.temp_allocator
+    context = task.ctx
+    context.temp_allocator = temp
+    task.fn(task.data)
 }
 
-spawn :: proc(fn: proc()) {
+spawn :: proc(fn: proc(), origin := "") {
     fn_copy := new(proc())
     fn_copy^ = fn
-    queue_push(&_pool.queue, Task{
+    t := Task{
         fn = proc(data: rawptr) {
             f := cast(^proc())data
             f^()
             free(f)
         },
         data = fn_copy,
-    })
+        origin = origin,
+        ctx = context,
+    }
+    if race_enabled do t.race = _race_fork()
+    _submit(t)
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// DETERMINISTIC SCHEDULER — golden -deterministic
+// ═══════════════════════════════════════════════════════════════════
+//
+// For reproducible tests. A goroutine still runs on a thread of its own
+// (it needs its own stack), but a baton lets only one of them run at a
+// time. A thread whose goroutine exited waits for the next spawn, so a
+// program holds only as many threads as it ever had goroutines at once.
+// The holder switches only at channel, WaitGroup and Mutex operations
+// (and Sleep); there a PRNG seeded by sched_deterministic picks who runs
+// next, so the same seed gives the same interleaving.
+//
+// Blocking waits are polls: a goroutine that can't proceed is marked
+// waiting and passes the baton on; it is considered again after some
+// other goroutine reaches a switch point or exits. Sleep uses a virtual
+// clock that jumps forward once nobody else can run. Timers keep to the
+// wall clock, so programs that race timers are only as deterministic as
+// their timing.
+
+_Det_G :: struct {
+    turn:    sync.Sema,      // posted when this goroutine gets the baton
+    thread:  ^thread.Thread, // nil for main
+    task:    Task,
+    waiting: bool,           // blocked since the last switch point
+    wake_at: i64,            // virtual time a sleeper resumes at
+}
+
+_det: struct {
+    enabled: bool,
+    seed:    u64,
+    rng:     u64,
+    now:     i64,               // virtual clock, nanoseconds
+    polled:  bool,              // every waiter re-checked since the last switch point
+    gs:      [dynamic]^_Det_G,  // live goroutines in spawn order, main first
+    idle:    [dynamic]^_Det_G,  // exited, their threads waiting for a new task
+    all:     [dynamic]^_Det_G,  // every thread started, joined at pool_stop
+    main:    _Det_G,
+}
+
+// The goroutine this thread runs; nil on runtime threads (timers).
+@(thread_local) _det_self: ^_Det_G
+
+// DET_TIMER_POLL is how long an otherwise stuck schedule waits for a
+// pending timer before polling again.
+DET_TIMER_POLL :: time.Millisecond
+
+// sched_deterministic makes pool_start use the deterministic scheduler.
+// GOLDEN_SEED in the environment overrides seed, to replay a schedule
+// without regenerating the program.
+sched_deterministic :: proc(seed: u64) {
+    _det.enabled = true
+    _det.seed = seed
+    if s := os.get_env("GOLDEN_SEED", context.temp_allocator); s != "" {
+        if v, ok := strconv.parse_u64(s); ok do _det.seed = v
+    }
+    _det.rng = _det.seed
+}
+
+_det_start :: proc() {
+    _det.gs = make([dynamic]^_Det_G, runtime.heap_allocator())
+    _det.idle = make([dynamic]^_Det_G, runtime.heap_allocator())
+    _det.all = make([dynamic]^_Det_G, runtime.heap_allocator())
+    _det_self = &_det.main
+    append(&_det.gs, &_det.main)
+}
+
+// _det_stop runs in pool_stop: main waits for every other goroutine.
+_det_stop :: proc() {
+    for len(_det.gs) > 1 {
+        _det_block()
+    }
+    // Every thread is idle now; a turn without a task tells it to exit
+    for dg in _det.all {
+        sync.sema_post(&dg.turn)
+        thread.join(dg.thread)
+        thread.destroy(dg.thread)
+        free(dg, runtime.heap_allocator())
+    }
+    delete(_det.gs)
+    delete(_det.idle)
+    delete(_det.all)
+    _det = {}
+    _det_self = nil
+}
+
+_det_spawn :: proc(t: Task) {
+    dg: ^_Det_G
+    if len(_det.idle) > 0 {
+        dg = pop(&_det.idle)
+    } else {
+        dg = new(_Det_G, runtime.heap_allocator())
+        dg.thread = thread.create(_det_proc)
+        dg.thread.data = dg
+        append(&_det.all, dg)
+        thread.start(dg.thread)
+    }
+    dg.task = t
+    dg.waiting = false
+    dg.wake_at = _det.now
+    append(&_det.gs, dg)
+}
+
+// _det_proc runs the goroutines _det_spawn hands its thread, one after
+// another; a turn with no task means pool_stop is done with it.
+_det_proc :: proc(th: ^thread.Thread) {
+    dg := cast(^_Det_G)th.data
+    _det_self = dg
+    for {
+        sync.sema_wait(&dg.turn)
+        if dg.task.fn == nil do return
+
+        g := Goroutine{origin = dg.task.origin}
+        _g_register(&g)
+        _current_g = &g
+        if race_enabled do _race_start(&g, dg.task.race)
+        _run_task(dg.task)
+        if race_enabled do _race_end(&g)
+        _current_g = nil
+        _g_unregister(&g)
+
+        for other, i in _det.gs {
+            if other == dg {
+                ordered_remove(&_det.gs, i)
+                break
+            }
+        }
+        dg.task = {}
+        append(&_det.idle, dg)
+        _det_progress()
+        sync.sema_post(&_det_pick().turn)
+    }
+}
+
+// _sched_point is a switch point of the deterministic scheduler.
+_sched_point :: proc() {
+    if _det.enabled && _det_self != nil {
+        _det_progress()
+        _det_switch()
+    }
+}
+
+// _det_block passes the baton on until the caller may have been unblocked.
+_det_block :: proc() {
+    _det_self.waiting = true
+    _det_switch()
+}
+
+_det_sleep :: proc(d: time.Duration) {
+    _det_self.wake_at = _det.now + i64(d)
+    _det_progress()
+    _det_switch()
+}
+
+// _det_progress wakes every waiter: whatever the running goroutine did
+// since its last switch point may have unblocked them.
+_det_progress :: proc() {
+    _det.polled = false
+    for g in _det.gs {
+        g.waiting = false
+    }
+}
+
+_det_switch :: proc() {
+    self := _det_self
+    next := _det_pick()
+    if next == self do return
+    sync.sema_post(&next.turn)
+    sync.sema_wait(&self.turn)
+}
+
+_det_ready :: proc(g: ^_Det_G) -> bool {
+    return !g.waiting && g.wake_at <= _det.now
+}
+
+// _det_pick chooses the next goroutine to run, or aborts on a deadlock.
+_det_pick :: proc() -> ^_Det_G {
+    for {
+        n := 0
+        for g in _det.gs {
+            if _det_ready(g) do n += 1
+        }
+        if n > 0 {
+            k := int(_det_rand() % u64(n))
+            for g in _det.gs {
+                if !_det_ready(g) do continue
+                if k == 0 do return g
+                k -= 1
+            }
+        }
+        // Nobody can run: wake the earliest sleeper,
+        wake := max(i64)
+        for g in _det.gs {
+            if !g.waiting && g.wake_at > _det.now do wake = min(wake, g.wake_at)
+        }
+        if wake != max(i64) {
+            _det.now = wake
+            continue
+        }
+        // else let every waiter re-check once, as a timer may have fired,
+        if !_det.polled {
+            _det_progress()
+            _det.polled = true
+            continue
+        }
+        // else wait for the timers still pending.
+        if sync.atomic_load(&_timers.live) > 0 {
+            time.sleep(DET_TIMER_POLL)
+            _det.polled = false
+            continue
+        }
+        sync.mutex_lock(&_sched.mu)
+        _deadlock_abort()
+    }
+}
+
+_det_rand :: proc() -> u64 {
+    return _splitmix(&_det.rng)
+}
+
+// _splitmix is splitmix64: tiny, and the same on every platform.
+_splitmix :: proc(state: ^u64) -> u64 {
+    state^ += 0x9e3779b97f4a7c15
+    z := state^
+    z = (z ~ (z >> 30)) * 0xbf58476d1ce4e5b9
+    z = (z ~ (z >> 27)) * 0x94d049bb133111eb
+    return z ~ (z >> 31)
+}
+
+// _cond_wait is sync.cond_wait for goroutine-facing waits. Under the
+// deterministic scheduler it passes the baton on instead; callers loop
+// on their condition anyway.
+_cond_wait :: proc(cv: ^sync.Cond, m: ^sync.Mutex) {
+    if !_det.enabled {
+        sync.cond_wait(cv, m)
+        return
+    }
+    sync.mutex_unlock(m)
+    _det_block()
+    sync.mutex_lock(m)
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// GOROUTINE TRACKING — Deadlock Detection
+// ═══════════════════════════════════════════════════════════════════
+//
+// Every running task is a Goroutine; main is goroutine 1. Blocking calls
+// record what they wait on. A watchdog thread aborts the program, Go
+// style, once no task is queued and every goroutine has been asleep for
+// two consecutive checks with no wake-up in between.
+
+Wait_Reason :: enum {
+    None,
+    Chan_Send,
+    Chan_Recv,
+    WaitGroup,
+    Mutex,
+    RW_Mutex,
+    Cond,
+    Once,
+    Select,
+    Sleep, // always wakes up, so never part of a deadlock
+    Exit,  // main in pool_stop, waiting for the remaining goroutines
+}
+
+_wait_names := [Wait_Reason]string{
+    .None      = "running",
+    .Chan_Send = "chan send",
+    .Chan_Recv = "chan receive",
+    .WaitGroup = "sync.WaitGroup.Wait",
+    .Mutex     = "sync.Mutex.Lock",
+    .RW_Mutex  = "sync.RWMutex.Lock",
+    .Cond      = "sync.Cond.Wait",
+    .Once      = "sync.Once.Do",
+    .Select    = "select",
+    .Sleep     = "sleep",
+    .Exit      = "waiting for goroutines at exit",
+}
+
+Goroutine :: struct {
+    id:       int,
+    origin:   string,
+    wait:     Wait_Reason,
+    wait_loc: runtime.Source_Code_Location,
+    race_tid: int,          // -race only
+    race_vc:  Vector_Clock,
+}
+
+_sched: struct {
+    mu:       sync.Mutex,            // zero-init
+    gs:       [dynamic]^Goroutine,   // running tasks, main excluded
+    next_id:  int,
+    asleep:   int,                   // goroutines (main included) in a wait that can deadlock
+    progress: int,                   // bumped on every wake-up
+    watchdog: ^thread.Thread,
+    stop:     bool,                  // atomic
+}
+
+_main_g := Goroutine{id = 1, origin = "main"}
+
+@(thread_local) _current_g: ^Goroutine
+
+DEADLOCK_CHECK_INTERVAL :: 100 * time.Millisecond
+
+_g_register :: proc(g: ^Goroutine) {
+    sync.mutex_lock(&_sched.mu)
+    defer sync.mutex_unlock(&_sched.mu)
+    _sched.next_id += 1
+    g.id = _sched.next_id
+    append(&_sched.gs, g)
+}
+
+_g_unregister :: proc(g: ^Goroutine) {
+    sync.mutex_lock(&_sched.mu)
+    defer sync.mutex_unlock(&_sched.mu)
+    for other, i in _sched.gs {
+        if other == g {
+            unordered_remove(&_sched.gs, i)
+            return
+        }
+    }
+}
+
+// _g_wait sets what the current goroutine waits on (.None once awake).
+_g_wait :: proc(reason: Wait_Reason, loc: runtime.Source_Code_Location) {
+    g := _current_g if _current_g != nil else &_main_g
+    sync.mutex_lock(&_sched.mu)
+    defer sync.mutex_unlock(&_sched.mu)
+    counts := proc(r: Wait_Reason) -> bool { return r != .None && r != .Sleep }
+    if counts(g.wait) {
+        _sched.asleep -= 1
+        _sched.progress += 1
+    }
+    if counts(reason) {
+        _sched.asleep += 1
+    }
+    g.wait = reason
+    g.wait_loc = loc
+}
+
+_watchdog_proc :: proc(t: ^thread.Thread) {
+    _runtime_thread = true
+    suspect := false
+    last := 0
+    for !sync.atomic_load(&_sched.stop) {
+        time.sleep(DEADLOCK_CHECK_INTERVAL)
+        sync.mutex_lock(&_sched.mu)
+        // A pending timer will still wake someone up
+        stuck := sync.atomic_load(&_pool.pending) == 0 && _sched.asleep == len(_sched.gs) + 1 && sync.atomic_load(&_timers.live) == 0
+        if stuck && suspect && _sched.progress == last {
+            _deadlock_abort()
+        }
+        suspect = stuck
+        last = _sched.progress
+        sync.mutex_unlock(&_sched.mu)
+    }
+}
+
+// _deadlock_abort prints every goroutine and exits like the Go runtime.
+// Called with _sched.mu held.
+_deadlock_abort :: proc() -> ! {
+    fmt.eprintln("fatal error: all goroutines are asleep - deadlock!")
+    _g_dump(&_main_g)
+    for g in _sched.gs {
+        _g_dump(g)
+    }
+    if _det.enabled {
+        fmt.eprintf("\nreplay this schedule with GOLDEN_SEED=%d\n", _det.seed)
+    }
+    os.exit(2)
+}
+
+_g_dump :: proc(g: ^Goroutine) {
+    fmt.eprintf("\ngoroutine %d [%s]:\n", g.id, _wait_names[g.wait])
+    if g.wait_loc.file_path != "" {
+        fmt.eprintf("\t%s\n", source_pos(g.wait_loc))
+    }
+    if g.id != 1 {
+        fmt.eprintf("created by go statement at %s\n", g.origin)
+    }
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// RACE DETECTOR — golden -race
+// ═══════════════════════════════════════════════════════════════════
+//
+// A vector-clock happens-before detector, switched on by the generated
+// main in -race builds. The transpiler calls race_read/race_write ahead
+// of statements that touch variables goroutines share (captured by
+// reference, or ARC data); the runtime adds the edges Go guarantees:
+// go statement → goroutine start, send → receive, close → receive,
+// WaitGroup Done → Wait, Unlock → Lock and Once. Atomics and Cond are
+// not modelled.
+//
+// Everything runs under one lock: this is a debugging mode.
+
+race_enabled: bool
+
+RACE_EXIT_CODE :: 66 // what Go's race detector exits with
+
+Vector_Clock :: [dynamic]u32
+
+_Race_Access :: struct {
+    tid:    int,
+    clock:  u32, // 0: no access
+    write:  bool,
+    g:      int,
+    origin: string,
+    loc:    runtime.Source_Code_Location,
+}
+
+_Race_Shadow :: struct {
+    write: _Race_Access,
+    reads: [dynamic]_Race_Access, // since the last write, one per thread
+}
+
+_race: struct {
+    mu:       sync.Mutex, // zero-init
+    shadow:   map[uintptr]_Race_Shadow,
+    clocks:   map[uintptr]Vector_Clock, // release clocks of sync objects
+    reported: map[string]bool,
+    next_tid: int,
+    races:    int,
+}
+
+// The timer and watchdog threads aren't goroutines; they add no edges.
+@(thread_local) _runtime_thread: bool
+
+race_read :: proc(addr: rawptr, loc := #caller_location) {
+    if race_enabled do _race_access(uintptr(addr), false, loc)
+}
+
+race_write :: proc(addr: rawptr, loc := #caller_location) {
+    if race_enabled do _race_access(uintptr(addr), true, loc)
+}
+
+_race_access :: proc(addr: uintptr, write: bool, loc: runtime.Source_Code_Location) {
+    sync.mutex_lock(&_race.mu)
+    defer sync.mutex_unlock(&_race.mu)
+    g := _race_g()
+    if g == nil do return
+
+    cur := _Race_Access{
+        tid = g.race_tid, clock = _vc_get(g.race_vc, g.race_tid), write = write,
+        g = g.id, origin = g.origin, loc = loc,
+    }
+    if addr not_in _race.shadow {
+        _race.shadow[addr] = _Race_Shadow{reads = make([dynamic]_Race_Access, runtime.heap_allocator())}
+    }
+    s := &_race.shadow[addr]
+
+    if w := s.write; w.clock != 0 && w.tid != cur.tid && w.clock > _vc_get(g.race_vc, w.tid) {
+        _race_report(addr, cur, w)
+    }
+    if write {
+        for r in s.reads {
+            if r.tid != cur.tid && r.clock > _vc_get(g.race_vc, r.tid) {
+                _race_report(addr, cur, r)
+            }
+        }
+        s.write = cur
+        clear(&s.reads)
+        return
+    }
+    for &r in s.reads {
+        if r.tid == cur.tid {
+            r = cur
+            return
+        }
+    }
+    append(&s.reads, cur)
+}
+
+// _race_g is the calling goroutine, nil on runtime threads. Called with
+// _race.mu held.
+_race_g :: proc() -> ^Goroutine {
+    if _runtime_thread do return nil
+    if _race.shadow == nil {
+        _race.shadow = make(map[uintptr]_Race_Shadow, runtime.heap_allocator())
+        _race.clocks = make(map[uintptr]Vector_Clock, runtime.heap_allocator())
+        _race.reported = make(map[string]bool, runtime.heap_allocator())
+    }
+    g := _current_g if _current_g != nil else &_main_g
+    if cap(g.race_vc) == 0 do _race_enter(g, make(Vector_Clock, runtime.heap_allocator()))
+    return g
+}
+
+// _race_enter gives g a thread slot, starting from the clock it inherits.
+_race_enter :: proc(g: ^Goroutine, vc: Vector_Clock) {
+    g.race_tid = _race.next_tid
+    _race.next_tid += 1
+    g.race_vc = vc
+    _vc_tick(&g.race_vc, g.race_tid)
+}
+
+// _race_fork snapshots the spawner's clock for a new goroutine.
+_race_fork :: proc() -> Vector_Clock {
+    sync.mutex_lock(&_race.mu)
+    defer sync.mutex_unlock(&_race.mu)
+    vc := make(Vector_Clock, runtime.heap_allocator())
+    if g := _race_g(); g != nil {
+        _vc_join(&vc, g.race_vc)
+        _vc_tick(&g.race_vc, g.race_tid)
+    }
+    return vc
+}
+
+_race_start :: proc(g: ^Goroutine, vc: Vector_Clock) {
+    sync.mutex_lock(&_race.mu)
+    defer sync.mutex_unlock(&_race.mu)
+    _race_enter(g, vc)
+}
+
+_race_end :: proc(g: ^Goroutine) {
+    sync.mutex_lock(&_race.mu)
+    defer sync.mutex_unlock(&_race.mu)
+    delete(g.race_vc)
+}
+
+// _race_release publishes the caller's history through a sync object.
+_race_release :: proc(obj: rawptr) {
+    if !race_enabled do return
+    sync.mutex_lock(&_race.mu)
+    defer sync.mutex_unlock(&_race.mu)
+    g := _race_g()
+    if g == nil do return
+    key := uintptr(obj)
+    if key not_in _race.clocks {
+        _race.clocks[key] = make(Vector_Clock, runtime.heap_allocator())
+    }
+    _vc_join(&_race.clocks[key], g.race_vc)
+    _vc_tick(&g.race_vc, g.race_tid)
+}
+
+// _race_acquire makes everything released through obj visible to the caller.
+_race_acquire :: proc(obj: rawptr) {
+    if !race_enabled do return
+    sync.mutex_lock(&_race.mu)
+    defer sync.mutex_unlock(&_race.mu)
+    g := _race_g()
+    if g == nil do return
+    if vc, ok := _race.clocks[uintptr(obj)]; ok do _vc_join(&g.race_vc, vc)
+}
+
+// _race_forget drops shadow state for freed memory, so a new allocation
+// at the same address starts clean.
+_race_forget :: proc(p: rawptr, size: int) {
+    sync.mutex_lock(&_race.mu)
+    defer sync.mutex_unlock(&_race.mu)
+    lo, hi := uintptr(p), uintptr(p) + uintptr(size)
+    stale := make([dynamic]uintptr, context.temp_allocator)
+    for addr in _race.shadow {
+        if addr >= lo && addr < hi do append(&stale, addr)
+    }
+    for addr in stale {
+        delete(_race.shadow[addr].reads)
+        delete_key(&_race.shadow, addr)
+    }
+}
+
+_race_report :: proc(addr: uintptr, cur, prev: _Race_Access) {
+    a, b := source_pos(cur.loc), source_pos(prev.loc)
+    if a > b do a, b = b, a
+    key := fmt.tprintf("%s|%s", a, b)
+    if key in _race.reported do return
+    _race.reported[strings.clone(key, runtime.heap_allocator())] = true
+    _race.races += 1
+
+    who := proc(a: _Race_Access) -> string {
+        return "main goroutine" if a.g == _main_g.id else fmt.tprintf("goroutine %d", a.g)
+    }
+    fmt.eprintln("==================")
+    fmt.eprintln("WARNING: DATA RACE")
+    fmt.eprintf("%s at %p by %s:\n  %s\n\n", "Write" if cur.write else "Read",
+        rawptr(addr), who(cur), source_pos(cur.loc))
+    fmt.eprintf("Previous %s at %p by %s:\n  %s\n", "write" if prev.write else "read",
+        rawptr(addr), who(prev), source_pos(prev.loc))
+    accesses := [2]_Race_Access{cur, prev}
+    for acc in accesses {
+        if acc.g != _main_g.id {
+            fmt.eprintf("\nGoroutine %d created at:\n  %s\n", acc.g, acc.origin)
+        }
+    }
+    fmt.eprintln("==================")
+}
+
+// _race_exit runs at pool_stop: a program with races fails like Go's.
+_race_exit :: proc() {
+    if !race_enabled do return
+    if _race.races > 0 {
+        fmt.eprintf("Found %d data race(s)\n", _race.races)
+        os.exit(RACE_EXIT_CODE)
+    }
+    for _, s in _race.shadow do delete(s.reads)
+    for _, vc in _race.clocks do delete(vc)
+    for k in _race.reported do delete(k, runtime.heap_allocator())
+    delete(_race.shadow)
+    delete(_race.clocks)
+    delete(_race.reported)
+    delete(_main_g.race_vc)
+    _main_g.race_vc = nil
+    _race = {}
+}
+
+_vc_get :: proc(vc: Vector_Clock, tid: int) -> u32 {
+    return vc[tid] if tid < len(vc) else 0
+}
+
+_vc_tick :: proc(vc: ^Vector_Clock, tid: int) {
+    for len(vc^) <= tid do append(vc, 0)
+    vc^[tid] += 1
+}
+
+_vc_join :: proc(dst: ^Vector_Clock, src: Vector_Clock) {
+    for len(dst^) < len(src) do append(dst, 0)
+    for c, i in src do dst^[i] = max(dst^[i], c)
 }
 
 // ═══════════════════════════════════════════════════════════════════
@@ -211,6 +1221,7 @@ WaitGroup :: struct {
 wg_init :: proc(wg: ^WaitGroup) {}
 
 wg_add :: proc(wg: ^WaitGroup, delta: int) {
+    _sched_point()
     sync.mutex_lock(&wg.mu)
     wg.count += delta
     if wg.count == 0 {
@@ -220,21 +1231,262 @@ wg_add :: proc(wg: ^WaitGroup, delta: int) {
 }
 
 wg_done :: proc(wg: ^WaitGroup) {
+    _race_release(wg)
     wg_add(wg, -1)
 }
 
-wg_wait :: proc(wg: ^WaitGroup) {
+wg_wait :: proc(wg: ^WaitGroup, loc := #caller_location) {
+    _sched_point()
     sync.mutex_lock(&wg.mu)
-    for wg.count > 0 {
-        sync.cond_wait(&wg.cond, &wg.mu)
+    if wg.count > 0 {
+        blocking_begin(.WaitGroup, loc)
+        for wg.count > 0 {
+            _cond_wait(&wg.cond, &wg.mu)
+        }
+        blocking_end()
     }
     sync.mutex_unlock(&wg.mu)
+    _race_acquire(wg)
 }
 
 // spawn_raw submits a proc(rawptr) with a data pointer.
 // Used by the transpiler for goroutines that capture arguments.
-spawn_raw :: proc(fn: proc(rawptr), data: rawptr) {
-    queue_push(&_pool.queue, Task{fn = fn, data = data})
+spawn_raw :: proc(fn: proc(rawptr), data: rawptr, origin := "") {
+    t := Task{fn = fn, data = data, origin = origin, ctx = context}
+    if race_enabled do t.race = _race_fork()
+    _submit(t)
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// SYNC — sync and sync/atomic
+// ═══════════════════════════════════════════════════════════════════
+//
+// sync.Mutex and sync.RWMutex map to Odin's own types; only the waiting
+// side goes through here so the scheduler sees it. Everything else is a
+// small runtime type.
+
+rw_lock :: proc(m: ^sync.RW_Mutex, loc := #caller_location) {
+    _sched_point()
+    if !sync.rw_mutex_try_lock(m) {
+        blocking_begin(.RW_Mutex, loc)
+        if _det.enabled {
+            for !sync.rw_mutex_try_lock(m) do _det_block()
+        } else {
+            sync.rw_mutex_lock(m)
+        }
+        blocking_end()
+    }
+    _race_acquire(m)
+}
+
+rw_rlock :: proc(m: ^sync.RW_Mutex, loc := #caller_location) {
+    _sched_point()
+    if !sync.rw_mutex_try_shared_lock(m) {
+        blocking_begin(.RW_Mutex, loc)
+        if _det.enabled {
+            for !sync.rw_mutex_try_shared_lock(m) do _det_block()
+        } else {
+            sync.rw_mutex_shared_lock(m)
+        }
+        blocking_end()
+    }
+    _race_acquire(m)
+}
+
+rw_unlock :: proc(m: ^sync.RW_Mutex) {
+    _sched_point()
+    _race_release(m)
+    sync.rw_mutex_unlock(m)
+}
+
+rw_runlock :: proc(m: ^sync.RW_Mutex) {
+    _sched_point()
+    _race_release(m)
+    sync.rw_mutex_shared_unlock(m)
+}
+
+// Once runs its body a single time; late callers wait until it is done.
+Once :: struct {
+    mu:   sync.Mutex, // zero-init
+    done: bool,       // atomic
+}
+
+// once_begin reports whether the caller must run the body. If so, it
+// holds o until once_end.
+once_begin :: proc(o: ^Once, loc := #caller_location) -> bool {
+    if sync.atomic_load(&o.done) {
+        _race_acquire(o)
+        return false
+    }
+    if !sync.mutex_try_lock(&o.mu) {
+        blocking_begin(.Once, loc)
+        if _det.enabled {
+            for !sync.mutex_try_lock(&o.mu) do _det_block()
+        } else {
+            sync.mutex_lock(&o.mu)
+        }
+        blocking_end()
+    }
+    if o.done {
+        sync.mutex_unlock(&o.mu)
+        _race_acquire(o)
+        return false
+    }
+    return true
+}
+
+once_end :: proc(o: ^Once) {
+    _race_release(o)
+    sync.atomic_store(&o.done, true)
+    sync.mutex_unlock(&o.mu)
+}
+
+once_do :: proc(o: ^Once, fn: proc(), loc := #caller_location) {
+    if once_begin(o, loc) {
+        defer once_end(o)
+        fn()
+    }
+}
+
+// Cond is sync.Cond: a condition variable bound to its Locker L.
+Cond :: struct {
+    cond: sync.Cond, // zero-init
+    L:    ^sync.Mutex,
+    gen:  int,       // signals so far, deterministic scheduler only
+}
+
+cond_new :: proc(l: ^sync.Mutex, loc := #caller_location) -> ^Cond {
+    c := new(Cond, loc = loc)
+    c.L = l
+    return c
+}
+
+cond_wait :: proc(c: ^Cond, loc := #caller_location) {
+    blocking_begin(.Cond, loc)
+    if _det.enabled {
+        gen := c.gen
+        sync.mutex_unlock(c.L)
+        for c.gen == gen do _det_block()
+        for !sync.mutex_try_lock(c.L) do _det_block()
+    } else {
+        sync.cond_wait(&c.cond, c.L)
+    }
+    blocking_end()
+}
+
+cond_signal :: proc(c: ^Cond) {
+    if _det.enabled do c.gen += 1
+    sync.cond_signal(&c.cond)
+}
+
+cond_broadcast :: proc(c: ^Cond) {
+    if _det.enabled do c.gen += 1
+    sync.cond_broadcast(&c.cond)
+}
+
+// Sync_Map is sync.Map with the key and value types the transpiler
+// inferred from its Store calls.
+Sync_Map :: struct($K, $V: typeid) {
+    mu: sync.RW_Mutex, // zero-init
+    m:  map[K]V,
+}
+
+sync_map_store :: proc(sm: ^Sync_Map($K, $V), key: K, value: V) {
+    sync.rw_mutex_lock(&sm.mu)
+    defer sync.rw_mutex_unlock(&sm.mu)
+    sm.m[key] = value
+}
+
+sync_map_load :: proc(sm: ^Sync_Map($K, $V), key: K) -> (V, bool) {
+    sync.rw_mutex_shared_lock(&sm.mu)
+    defer sync.rw_mutex_shared_unlock(&sm.mu)
+    v, ok := sm.m[key]
+    return v, ok
+}
+
+sync_map_load_or_store :: proc(sm: ^Sync_Map($K, $V), key: K, value: V) -> (actual: V, loaded: bool) {
+    sync.rw_mutex_lock(&sm.mu)
+    defer sync.rw_mutex_unlock(&sm.mu)
+    if v, ok := sm.m[key]; ok {
+        return v, true
+    }
+    sm.m[key] = value
+    return value, false
+}
+
+sync_map_load_and_delete :: proc(sm: ^Sync_Map($K, $V), key: K) -> (V, bool) {
+    sync.rw_mutex_lock(&sm.mu)
+    defer sync.rw_mutex_unlock(&sm.mu)
+    v, ok := sm.m[key]
+    delete_key(&sm.m, key)
+    return v, ok
+}
+
+sync_map_delete :: proc(sm: ^Sync_Map($K, $V), key: K) {
+    sync.rw_mutex_lock(&sm.mu)
+    defer sync.rw_mutex_unlock(&sm.mu)
+    delete_key(&sm.m, key)
+}
+
+// sync_map_snapshot copies the entries for Range, so the body may call
+// back into the map without deadlocking.
+sync_map_snapshot :: proc(sm: ^Sync_Map($K, $V)) -> map[K]V {
+    sync.rw_mutex_shared_lock(&sm.mu)
+    defer sync.rw_mutex_shared_unlock(&sm.mu)
+    out := make(map[K]V, len(sm.m), context.temp_allocator)
+    for k, v in sm.m {
+        out[k] = v
+    }
+    return out
+}
+
+sync_map_destroy :: proc(sm: ^Sync_Map($K, $V)) {
+    delete(sm.m)
+}
+
+// Sync_Pool is sync.Pool. Items are untyped pointers; the Get().(*T)
+// assertion becomes a cast.
+Sync_Pool :: struct {
+    mu:    sync.Mutex, // zero-init
+    items: [dynamic]rawptr,
+    New:   proc() -> rawptr,
+}
+
+sync_pool_get :: proc(p: ^Sync_Pool) -> rawptr {
+    sync.mutex_lock(&p.mu)
+    item, ok := pop_safe(&p.items)
+    sync.mutex_unlock(&p.mu)
+    if ok do return item
+    if p.New != nil do return p.New()
+    return nil
+}
+
+sync_pool_put :: proc(p: ^Sync_Pool, item: rawptr) {
+    if item == nil do return
+    sync.mutex_lock(&p.mu)
+    append(&p.items, item)
+    sync.mutex_unlock(&p.mu)
+}
+
+// sync_pool_destroy drops the pool's list; pooled items stay with
+// whoever allocated them.
+sync_pool_destroy :: proc(p: ^Sync_Pool) {
+    delete(p.items)
+}
+
+// Atomic backs atomic.Int32/Int64/Uint32/Uint64/Bool/Pointer[T].
+Atomic :: struct($T: typeid) {
+    v: T,
+}
+
+// atomic_add returns the new value, like Go's atomic.Add*.
+atomic_add :: proc(p: ^$T, delta: T) -> T {
+    return sync.atomic_add(p, delta) + delta
+}
+
+atomic_cas :: proc(p: ^$T, old, new: T) -> bool {
+    _, ok := sync.atomic_compare_exchange_strong(p, old, new)
+    return ok
 }
 
 // ═══════════════════════════════════════════════════════════════════
@@ -247,43 +1499,799 @@ Channel :: struct($T: typeid) {
     mu:        sync.Mutex,   // zero-init
     not_empty: sync.Cond,    // zero-init
     not_full:  sync.Cond,    // zero-init
+    closed:    bool,
 }
 
 // chan_make allocates a new generic channel on the heap
-chan_make :: proc($T: typeid) -> ^Channel(T) {
-    return new(Channel(T))
+chan_make :: proc($T: typeid, loc := #caller_location) -> ^Channel(T) {
+    return new(Channel(T), loc = loc)
 }
 
 // chan_send blocks until the channel is empty, then writes data
-chan_send :: proc(c: ^Channel($T), val: T) {
+chan_send :: proc(c: ^Channel($T), val: T, loc := #caller_location) {
+    _sched_point()
     sync.mutex_lock(&c.mu)
-    for c.has_data {
-        sync.cond_wait(&c.not_full, &c.mu)
+    if c.has_data && !c.closed {
+        blocking_begin(.Chan_Send, loc)
+        for c.has_data && !c.closed {
+            _cond_wait(&c.not_full, &c.mu)
+        }
+        blocking_end()
+    }
+    if c.closed {
+        sync.mutex_unlock(&c.mu)
+        panic("send on closed channel", loc)
     }
+    _race_release(c)
     c.data = val
     c.has_data = true
     sync.cond_signal(&c.not_empty)
+    _select_notify()
     sync.mutex_unlock(&c.mu)
 }
 
-// chan_recv blocks until the channel has data, then reads it
-chan_recv :: proc(c: ^Channel($T)) -> T {
+// chan_recv blocks until the channel has data, then reads it. A closed,
+// drained channel yields the zero value at once.
+chan_recv :: proc(c: ^Channel($T), loc := #caller_location) -> T {
+    _sched_point()
     sync.mutex_lock(&c.mu)
-    for !c.has_data {
-        sync.cond_wait(&c.not_empty, &c.mu)
+    if !c.has_data && !c.closed {
+        blocking_begin(.Chan_Recv, loc)
+        for !c.has_data && !c.closed {
+            _cond_wait(&c.not_empty, &c.mu)
+        }
+        blocking_end()
+    }
+    _race_acquire(c)
+    if !c.has_data {
+        sync.mutex_unlock(&c.mu)
+        return {}
     }
     val := c.data
     c.has_data = false
     sync.cond_signal(&c.not_full)
+    _select_notify()
     sync.mutex_unlock(&c.mu)
     return val
 }
 
+// chan_try_send writes val only if the channel is empty; it never blocks.
+chan_try_send :: proc(c: ^Channel($T), val: T) -> bool {
+    sync.mutex_lock(&c.mu)
+    defer sync.mutex_unlock(&c.mu)
+    if c.has_data || c.closed do return false
+    c.data = val
+    c.has_data = true
+    sync.cond_signal(&c.not_empty)
+    _select_notify()
+    return true
+}
+
+// chan_close is close(ch): every waiting receiver wakes up and further
+// receives return the zero value once the buffered value is read.
+chan_close :: proc(c: ^Channel($T), loc := #caller_location) {
+    _sched_point()
+    sync.mutex_lock(&c.mu)
+    defer sync.mutex_unlock(&c.mu)
+    if c.closed do panic("close of closed channel", loc)
+    _race_release(c)
+    c.closed = true
+    sync.cond_broadcast(&c.not_empty)
+    sync.cond_broadcast(&c.not_full)
+    _select_notify()
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// SELECT
+// ═══════════════════════════════════════════════════════════════════
+//
+// select_wait polls its cases, starting at a random one, and completes
+// the first that is ready. If none is and there is no default, it parks
+// until some channel changes state: every send, receive and close bumps
+// a global generation while selects are waiting. Timer deliveries and
+// context cancellations are channel operations too, so time.After,
+// tickers and ctx.Done() work as cases.
+
+Select_Case :: struct {
+    poll:  proc(sc: ^Select_Case) -> bool, // completes the operation if it can
+    ch:    rawptr,                         // nil never becomes ready
+    value: rawptr,                         // the value to send, or where to store the received one
+    ok:    ^bool,                          // receive: false once closed and drained
+}
+
+_select: struct {
+    mu:      sync.Mutex, // zero-init
+    cond:    sync.Cond,  // zero-init
+    gen:     int,
+    waiters: int,        // atomic: selects between their first poll and their return
+}
+
+@(thread_local) _select_rng: u64
+
+// select_recv is a `case v, ok := <-c` clause; value and ok may be nil.
+select_recv :: proc(c: ^Channel($T), value: ^T = nil, ok: ^bool = nil) -> Select_Case {
+    poll :: proc(sc: ^Select_Case) -> bool {
+        c := cast(^Channel(T))sc.ch
+        sync.mutex_lock(&c.mu)
+        defer sync.mutex_unlock(&c.mu)
+        if !c.has_data && !c.closed do return false
+        _race_acquire(c)
+        if sc.ok != nil do sc.ok^ = c.has_data
+        if !c.has_data do return true
+        if sc.value != nil do (cast(^T)sc.value)^ = c.data
+        c.has_data = false
+        sync.cond_signal(&c.not_full)
+        _select_notify()
+        return true
+    }
+    return Select_Case{poll = poll, ch = c, value = value, ok = ok}
+}
+
+// select_send is a `case c <- value^` clause. Like a send, it panics on
+// a closed channel.
+select_send :: proc(c: ^Channel($T), value: ^T) -> Select_Case {
+    poll :: proc(sc: ^Select_Case) -> bool {
+        c := cast(^Channel(T))sc.ch
+        sync.mutex_lock(&c.mu)
+        defer sync.mutex_unlock(&c.mu)
+        if c.closed do panic("send on closed channel")
+        if c.has_data do return false
+        _race_release(c)
+        c.data = (cast(^T)sc.value)^
+        c.has_data = true
+        sync.cond_signal(&c.not_empty)
+        _select_notify()
+        return true
+    }
+    return Select_Case{poll = poll, ch = c, value = value}
+}
+
+// select_wait runs a select statement and returns the index of the case
+// it completed, or len(cases) for the default.
+select_wait :: proc(cases: []Select_Case, has_default := false, loc := #caller_location) -> int {
+    _sched_point()
+    sync.atomic_add(&_select.waiters, 1)
+    defer sync.atomic_sub(&_select.waiters, 1)
+
+    start := 0
+    if len(cases) > 1 {
+        // Seeded schedules pick the same cases on every run
+        if _det.enabled {
+            start = int(_det_rand() % u64(len(cases)))
+        } else {
+            if _select_rng == 0 do _select_rng = u64(time.to_unix_nanoseconds(time.now()))
+            start = int(_splitmix(&_select_rng) % u64(len(cases)))
+        }
+    }
+    blocked := false
+    defer if blocked do blocking_end()
+    for {
+        sync.mutex_lock(&_select.mu)
+        gen := _select.gen
+        sync.mutex_unlock(&_select.mu)
+
+        for i in 0..<len(cases) {
+            k := (start + i) % len(cases)
+            if cases[k].ch != nil && cases[k].poll(&cases[k]) do return k
+        }
+        if has_default do return len(cases)
+
+        if !blocked {
+            blocking_begin(.Select, loc)
+            blocked = true
+        }
+        sync.mutex_lock(&_select.mu)
+        for _select.gen == gen {
+            _cond_wait(&_select.cond, &_select.mu)
+        }
+        sync.mutex_unlock(&_select.mu)
+    }
+}
+
+// _select_notify wakes the waiting selects after a channel changed state.
+_select_notify :: proc() {
+    if sync.atomic_load(&_select.waiters) == 0 do return
+    sync.mutex_lock(&_select.mu)
+    _select.gen += 1
+    sync.cond_broadcast(&_select.cond)
+    sync.mutex_unlock(&_select.mu)
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// TIMERS — time.After, time.NewTimer, time.NewTicker
+// ═══════════════════════════════════════════════════════════════════
+//
+// One timer thread sleeps until the earliest deadline in a min-heap and
+// delivers the current time on the timer's channel. Like Go, a delivery
+// never blocks: if the last tick is still unread, the new one is dropped.
+// Stop and Reset bump the timer's generation, which turns any heap entry
+// already queued for it stale instead of removing it.
+//
+// Timers belong to the runtime. The generated code detaches a timer
+// when its last handle goes out of scope (time.After is detached from
+// the start, a context's once the context is gone); a detached timer is
+// freed as soon as no heap entry points at it and nobody can still read
+// its tick. Timers with a callback run it on the timer thread instead of
+// sending on C.
+
+Timer :: struct {
+    C:        ^Channel(time.Time), // nil for callback timers
+    period:   time.Duration,       // 0 for one-shot timers
+    gen:      int,
+    active:   bool,
+    detached: bool,                // no handle left outside the runtime
+    dropped:  bool,                // nor any reader of C
+    queued:   int,                 // heap entries, live or stale
+    fn:       proc(rawptr),
+    data:     rawptr,
+}
+
+_Timer_Entry :: struct {
+    deadline: i64, // unix nanoseconds
+    timer:    ^Timer,
+    gen:      int,
+}
+
+_timers: struct {
+    mu:     sync.Mutex, // zero-init
+    cond:   sync.Cond,  // zero-init
+    queue:  [dynamic]_Timer_Entry,
+    all:    [dynamic]^Timer,
+    live:   int,        // active timers (atomic), read by the deadlock watchdog
+    thread: ^thread.Thread,
+    closed: bool,
+}
+
+_timer_less :: proc(a, b: _Timer_Entry) -> bool {
+    // slice/heap keeps the greatest element on top
+    return a.deadline > b.deadline
+}
+
+// _timer_schedule queues t to fire after d. Called with _timers.mu held.
+_timer_schedule :: proc(t: ^Timer, d: time.Duration) {
+    if !t.active {
+        t.active = true
+        sync.atomic_add(&_timers.live, 1)
+    }
+    deadline := time.to_unix_nanoseconds(time.now()) + i64(d)
+    _timer_push(t, deadline)
+    sync.cond_signal(&_timers.cond)
+}
+
+_timer_push :: proc(t: ^Timer, deadline: i64) {
+    t.queued += 1
+    append(&_timers.queue, _Timer_Entry{deadline = deadline, timer = t, gen = t.gen})
+    heap.push(_timers.queue[:], _timer_less)
+}
+
+// _timer_deactivate is called with _timers.mu held.
+_timer_deactivate :: proc(t: ^Timer) -> bool {
+    t.gen += 1
+    if !t.active do return false
+    t.active = false
+    sync.atomic_sub(&_timers.live, 1)
+    return true
+}
+
+_timer_new :: proc(d, period: time.Duration, detached: bool, fn: proc(rawptr) = nil, data: rawptr = nil) -> ^Timer {
+    // Runtime owned, so the tracking allocator doesn't see them as leaks
+    t := new(Timer, runtime.heap_allocator())
+    if fn == nil do t.C = new(Channel(time.Time), runtime.heap_allocator())
+    t.period = period
+    t.detached = detached
+    t.fn = fn
+    t.data = data
+
+    sync.mutex_lock(&_timers.mu)
+    defer sync.mutex_unlock(&_timers.mu)
+    if _timers.thread == nil {
+        _timers.queue = make([dynamic]_Timer_Entry, runtime.heap_allocator())
+        _timers.all = make([dynamic]^Timer, runtime.heap_allocator())
+        _timers.thread = thread.create(_timer_proc)
+        thread.start(_timers.thread)
+    }
+    append(&_timers.all, t)
+    _timer_schedule(t, d)
+    return t
+}
+
+timer_new :: proc(d: time.Duration) -> ^Timer {
+    return _timer_new(d, 0, false)
+}
+
+ticker_new :: proc(d: time.Duration) -> ^Timer {
+    assert(d > 0, "non-positive interval for NewTicker")
+    return _timer_new(d, d, false)
+}
+
+// time_after is time.After: a channel that receives once after d.
+time_after :: proc(d: time.Duration) -> ^Channel(time.Time) {
+    return _timer_new(d, 0, true).C
+}
+
+// timer_func runs fn(data) on the timer thread after d. The callback
+// must not block; it may stop, reset or create timers.
+timer_func :: proc(d: time.Duration, fn: proc(rawptr), data: rawptr) -> ^Timer {
+    return _timer_new(d, 0, false, fn, data)
+}
+
+// timer_detach hands t back to the runtime when its handle goes out of
+// scope: it is stopped and freed once no heap entry refers to it.
+timer_detach :: proc(t: ^Timer) {
+    sync.mutex_lock(&_timers.mu)
+    defer sync.mutex_unlock(&_timers.mu)
+    _timer_deactivate(t)
+    t.detached = true
+    t.dropped = true
+    _timers_sweep()
+}
+
+// time_after_done drops a time.After channel nobody will receive from,
+// such as the losing case of a select, so an unread tick doesn't keep
+// its timer alive.
+time_after_done :: proc(c: ^Channel(time.Time)) {
+    sync.mutex_lock(&_timers.mu)
+    defer sync.mutex_unlock(&_timers.mu)
+    for t in _timers.all {
+        if t.C != c do continue
+        _timer_deactivate(t)
+        t.dropped = true
+        break
+    }
+    _timers_sweep()
+}
+
+// timer_stop reports whether the call stopped a pending timer.
+timer_stop :: proc(t: ^Timer) -> bool {
+    sync.mutex_lock(&_timers.mu)
+    defer sync.mutex_unlock(&_timers.mu)
+    return _timer_deactivate(t)
+}
+
+// timer_reset reschedules t to fire after d (and every d for tickers).
+timer_reset :: proc(t: ^Timer, d: time.Duration) -> bool {
+    sync.mutex_lock(&_timers.mu)
+    defer sync.mutex_unlock(&_timers.mu)
+    was := _timer_deactivate(t)
+    if t.period > 0 do t.period = d
+    _timer_schedule(t, d)
+    return was
+}
+
+_timer_proc :: proc(th: ^thread.Thread) {
+    _runtime_thread = true
+    sync.mutex_lock(&_timers.mu)
+    defer sync.mutex_unlock(&_timers.mu)
+    for !_timers.closed {
+        if len(_timers.queue) == 0 {
+            sync.cond_wait(&_timers.cond, &_timers.mu)
+            continue
+        }
+        next := _timers.queue[0]
+        wait := time.Duration(next.deadline - time.to_unix_nanoseconds(time.now()))
+        if wait > 0 {
+            sync.cond_wait_with_timeout(&_timers.cond, &_timers.mu, wait)
+            continue
+        }
+        heap.pop(_timers.queue[:], _timer_less)
+        pop(&_timers.queue)
+
+        t := next.timer
+        t.queued -= 1
+        if next.gen != t.gen || !t.active { // stopped or reset
+            _timers_sweep()
+            continue
+        }
+        if t.period > 0 {
+            _timer_push(t, next.deadline + i64(t.period))
+        } else {
+            _timer_deactivate(t)
+        }
+        if t.fn != nil {
+            // The callback may take timer locks of its own
+            fn, data := t.fn, t.data
+            sync.mutex_unlock(&_timers.mu)
+            fn(data)
+            sync.mutex_lock(&_timers.mu)
+        } else {
+            chan_try_send(t.C, time.now())
+        }
+        _timers_sweep()
+    }
+}
+
+// _timers_sweep frees detached timers that are done. Called with
+// _timers.mu held.
+_timers_sweep :: proc() {
+    for i := len(_timers.all) - 1; i >= 0; i -= 1 {
+        t := _timers.all[i]
+        if !t.detached || t.active || t.queued > 0 do continue
+        if t.C != nil && !t.dropped && sync.atomic_load(&t.C.has_data) do continue
+        unordered_remove(&_timers.all, i)
+        _timer_free(t)
+    }
+}
+
+_timer_free :: proc(t: ^Timer) {
+    if t.C != nil do free(t.C, runtime.heap_allocator())
+    free(t, runtime.heap_allocator())
+}
+
+_timers_stop :: proc() {
+    sync.mutex_lock(&_timers.mu)
+    if _timers.thread == nil {
+        sync.mutex_unlock(&_timers.mu)
+        return
+    }
+    _timers.closed = true
+    sync.cond_signal(&_timers.cond)
+    sync.mutex_unlock(&_timers.mu)
+
+    thread.join(_timers.thread)
+    thread.destroy(_timers.thread)
+    for t in _timers.all do _timer_free(t)
+    delete(_timers.all)
+    delete(_timers.queue)
+    _timers = {}
+}
+
+// duration_string formats d the way Go's Duration.String does: 1h2m3.5s,
+// 1.5ms, 0s.
+duration_string :: proc(d: time.Duration) -> string {
+    if d == 0 do return "0s"
+    neg := d < 0
+    u := abs(i64(d))
+    sign := "-" if neg else ""
+    switch {
+    case u < 1_000:
+        return fmt.tprintf("%s%dns", sign, u)
+    case u < 1_000_000:
+        return fmt.tprintf("%s%vµs", sign, f64(u) / 1e3)
+    case u < 1_000_000_000:
+        return fmt.tprintf("%s%vms", sign, f64(u) / 1e6)
+    }
+    h := u / 3_600_000_000_000
+    m := (u / 60_000_000_000) % 60
+    sec := f64(u % 60_000_000_000) / 1e9
+    if h > 0 do return fmt.tprintf("%s%dh%dm%vs", sign, h, m, sec)
+    if m > 0 do return fmt.tprintf("%s%dm%vs", sign, m, sec)
+    return fmt.tprintf("%s%vs", sign, sec)
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// CONTEXT — context.Context cancellation trees
+// ═══════════════════════════════════════════════════════════════════
+//
+// A context is an ARC-managed Ctx_Node. Children hold a strong reference
+// to their parent and parents list their children without owning them,
+// so a subtree is freed the moment its last handle is released: no
+// finalizer, no background goroutine. context.Background is the nil node.
+//
+// Go code passes contexts around as ^Ctx_Node borrowed from the owning
+// Arc, like any other ARC value. A CancelFunc is a generation-checked
+// slot, so it can be copied, called twice, or called after its context
+// is gone.
+
+Empty :: struct {}
+
+Context :: Arc(Ctx_Node)
+
+Ctx_Node :: struct {
+    counts:       ^Arc_Counts, // lets a borrowed pointer take a reference
+    allocator:    mem.Allocator,
+    parent:       Context,
+    mu:           sync.Mutex,
+    done:         ^Channel(Empty),
+    err:          cstring,
+    children:     [dynamic]^Ctx_Node,
+    cancel:       Cancel_Func,
+    deadline:     time.Time,
+    has_deadline: bool,
+    timer:        ^Timer,      // holds a reference until it fires or stops
+    key:          string,
+    key_type:     typeid,
+    value:        rawptr,
+    value_type:   typeid,
+}
+
+Cancel_Func :: struct {
+    slot: int,
+    gen:  u32, // 0 for a zero CancelFunc
+}
+
+_Cancel_Slot :: struct {
+    node: ^Ctx_Node,
+    gen:  u32,
+}
+
+context_canceled:          cstring = "context canceled"
+context_deadline_exceeded: cstring = "context deadline exceeded"
+
+// Done of the background context: never closed.
+_ctx_never: Channel(Empty)
+
+_cancels: struct {
+    mu:    sync.Mutex, // zero-init
+    slots: [dynamic]_Cancel_Slot,
+    free:  [dynamic]int,
+}
+
+context_background :: proc() -> ^Ctx_Node { return nil }
+
+with_cancel :: proc(parent: ^Ctx_Node, loc := #caller_location) -> (Context, Cancel_Func) {
+    c := _ctx_new(parent, true, loc)
+    return c, c.data.cancel
+}
+
+with_timeout :: proc(parent: ^Ctx_Node, d: time.Duration, loc := #caller_location) -> (Context, Cancel_Func) {
+    return with_deadline(parent, time.time_add(time.now(), d), loc)
+}
+
+with_deadline :: proc(parent: ^Ctx_Node, d: time.Time, loc := #caller_location) -> (Context, Cancel_Func) {
+    c := _ctx_new(parent, true, loc)
+    n := c.data
+    if n.has_deadline && time.diff(n.deadline, d) >= 0 {
+        return c, n.cancel // the parent expires first
+    }
+    n.deadline, n.has_deadline = d, true
+
+    wait := time.diff(time.now(), d)
+    if wait <= 0 {
+        _ctx_cancel(n, context_deadline_exceeded)
+        return c, n.cancel
+    }
+    sync.mutex_lock(&n.mu)
+    if n.err == nil {
+        _ = retain(c)
+        n.timer = timer_func(wait, _ctx_timeout, n)
+    }
+    sync.mutex_unlock(&n.mu)
+    return c, n.cancel
+}
+
+// with_value boxes key and value; lookups match on both the key's type
+// and its printed form, the way Go compares interface keys.
+with_value :: proc(parent: ^Ctx_Node, key: $K, value: $V, loc := #caller_location) -> Context {
+    c := _ctx_new(parent, false, loc)
+    n := c.data
+    n.key = fmt.aprint(key, allocator = n.allocator)
+    n.key_type = typeid_of(K)
+    n.value = new_clone(value, n.allocator, loc)
+    n.value_type = typeid_of(V)
+    return c
+}
+
+cancel_call :: proc(f: Cancel_Func) {
+    if f.gen == 0 do return
+    n: ^Ctx_Node
+    sync.mutex_lock(&_cancels.mu)
+    if f.slot < len(_cancels.slots) && _cancels.slots[f.slot].gen == f.gen {
+        n = _cancels.slots[f.slot].node
+        if !_arc_try_retain(n.counts) do n = nil
+    }
+    sync.mutex_unlock(&_cancels.mu)
+    if n == nil do return
+
+    ref := Context{data = n, ref_count = n.counts}
+    _ctx_cancel(n, context_canceled)
+    arc_release(&ref)
+}
+
+context_done :: proc(ctx: ^Ctx_Node) -> ^Channel(Empty) {
+    if ctx == nil do return &_ctx_never
+    return ctx.done
+}
+
+context_err :: proc(ctx: ^Ctx_Node) -> cstring {
+    if ctx == nil do return nil
+    sync.mutex_lock(&ctx.mu)
+    defer sync.mutex_unlock(&ctx.mu)
+    return ctx.err
+}
+
+context_deadline :: proc(ctx: ^Ctx_Node) -> (time.Time, bool) {
+    if ctx == nil do return {}, false
+    return ctx.deadline, ctx.has_deadline
+}
+
+// context_value is ctx.Value(key) as an untyped pointer, nil if absent.
+context_value :: proc(ctx: ^Ctx_Node, key: $K) -> rawptr {
+    k := fmt.tprint(key)
+    for n := ctx; n != nil; n = n.parent.data {
+        if n.key_type == typeid_of(K) && n.key == k do return n.value
+    }
+    return nil
+}
+
+// context_value_as is ctx.Value(key).(T), panicking like a failed
+// type assertion.
+context_value_as :: proc(ctx: ^Ctx_Node, key: $K, $T: typeid, loc := #caller_location) -> T {
+    k := fmt.tprint(key)
+    for n := ctx; n != nil; n = n.parent.data {
+        if n.key_type != typeid_of(K) || n.key != k do continue
+        if n.value_type != T {
+            panic(fmt.tprintf("interface conversion: interface {} is %v, not %v", n.value_type, typeid_of(T)), loc)
+        }
+        return (cast(^T)n.value)^
+    }
+    panic(fmt.tprintf("interface conversion: interface {} is nil, not %v", typeid_of(T)), loc)
+}
+
+_ctx_new :: proc(parent: ^Ctx_Node, cancelable: bool, loc: runtime.Source_Code_Location) -> Context {
+    c := make_arc(Ctx_Node{}, _ctx_drop, loc)
+    n := c.data
+    n.counts = c.ref_count
+    n.allocator = context.allocator
+    n.done = new(Channel(Empty), n.allocator, loc)
+    n.children.allocator = n.allocator
+    if cancelable do n.cancel = _cancel_slot_new(n)
+    if parent == nil do return c
+
+    n.parent = retain(Context{data = parent, ref_count = parent.counts})
+    n.deadline, n.has_deadline = parent.deadline, parent.has_deadline
+    sync.mutex_lock(&parent.mu)
+    err := parent.err
+    if err == nil do append(&parent.children, n)
+    sync.mutex_unlock(&parent.mu)
+    if err != nil do _ctx_cancel(n, err)
+    return c
+}
+
+// _ctx_cancel closes n's Done channel and cancels its subtree. The first
+// error wins.
+_ctx_cancel :: proc(n: ^Ctx_Node, err: cstring) {
+    sync.mutex_lock(&n.mu)
+    if n.err != nil {
+        sync.mutex_unlock(&n.mu)
+        return
+    }
+    n.err = err
+    // Hold each child while cancelling it: its last handle may be
+    // released concurrently. A child already being dropped is skipped.
+    children := make([dynamic]Context, 0, len(n.children), runtime.heap_allocator())
+    for child in n.children {
+        if _arc_try_retain(child.counts) {
+            append(&children, Context{data = child, ref_count = child.counts})
+        }
+    }
+    timer := n.timer
+    sync.mutex_unlock(&n.mu)
+
+    chan_close(n.done)
+    for &child in children {
+        _ctx_cancel(child.data, err)
+        arc_release(&child)
+    }
+    delete(children)
+
+    if timer != nil && timer_stop(timer) {
+        // The pending timer's reference is ours to drop
+        self := Context{data = n, ref_count = n.counts}
+        arc_release(&self)
+    }
+}
+
+_ctx_timeout :: proc(data: rawptr) {
+    n := cast(^Ctx_Node)data
+    _ctx_cancel(n, context_deadline_exceeded)
+    self := Context{data = n, ref_count = n.counts}
+    arc_release(&self)
+}
+
+_ctx_drop :: proc(data: rawptr) {
+    n := cast(^Ctx_Node)data
+    if parent := n.parent.data; parent != nil {
+        sync.mutex_lock(&parent.mu)
+        for child, i in parent.children {
+            if child == n {
+                unordered_remove(&parent.children, i)
+                break
+            }
+        }
+        sync.mutex_unlock(&parent.mu)
+        arc_release(&n.parent)
+    }
+    if n.timer != nil do timer_detach(n.timer)
+    _cancel_slot_free(n.cancel)
+    free(n.done, n.allocator)
+    delete(n.children)
+    delete(n.key, n.allocator)
+    free(n.value, n.allocator)
+}
+
+_cancel_slot_new :: proc(n: ^Ctx_Node) -> Cancel_Func {
+    sync.mutex_lock(&_cancels.mu)
+    defer sync.mutex_unlock(&_cancels.mu)
+    if cap(_cancels.slots) == 0 {
+        // Runtime owned, like timers
+        _cancels.slots = make([dynamic]_Cancel_Slot, runtime.heap_allocator())
+        _cancels.free = make([dynamic]int, runtime.heap_allocator())
+    }
+    i: int
+    if len(_cancels.free) > 0 {
+        i = pop(&_cancels.free)
+    } else {
+        append(&_cancels.slots, _Cancel_Slot{})
+        i = len(_cancels.slots) - 1
+    }
+    s := &_cancels.slots[i]
+    s.node = n
+    s.gen += 1
+    return Cancel_Func{slot = i, gen = s.gen}
+}
+
+_cancel_slot_free :: proc(f: Cancel_Func) {
+    if f.gen == 0 do return
+    sync.mutex_lock(&_cancels.mu)
+    defer sync.mutex_unlock(&_cancels.mu)
+    s := &_cancels.slots[f.slot]
+    s.node = nil
+    s.gen += 1
+    append(&_cancels.free, f.slot)
+}
+
+_cancels_destroy :: proc() {
+    delete(_cancels.slots)
+    delete(_cancels.free)
+    _cancels = {}
+}
+
 // ═══════════════════════════════════════════════════════════════════
 // ERRORS
 // ═══════════════════════════════════════════════════════════════════
 
 // error_new converts an Odin string to a C-string so it can be nil-checked
-error_new :: proc(msg: string) -> cstring {
-    return strings.clone_to_cstring(msg)
+error_new :: proc(msg: string, loc := #caller_location) -> cstring {
+    return strings.clone_to_cstring(msg, loc = loc)
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// SOURCE MAP — Report against the Go source, not main.odin
+// ═══════════════════════════════════════════════════════════════════
+
+// Source_Line maps a generated main.odin line to the Go position it
+// came from. The transpiler emits a sorted table of these.
+Source_Line :: struct {
+    line:    i32,
+    file:    string,
+    go_line: i32,
+    go_col:  i32,
+}
+
+_source_map: []Source_Line
+
+source_map_install :: proc(table: []Source_Line) {
+    _source_map = table
+}
+
+// source_pos renders loc as file.go:line:col when it points into the
+// generated code, falling back to the Odin location otherwise.
+source_pos :: proc(loc: runtime.Source_Code_Location) -> string {
+    if strings.has_suffix(loc.file_path, "main.odin") {
+        // Lines without an entry belong to the closest entry above them
+        best := -1
+        for entry, i in _source_map {
+            if entry.line > loc.line do break
+            best = i
+        }
+        if best >= 0 {
+            e := _source_map[best]
+            return fmt.tprintf("%s:%d:%d", e.file, e.go_line, e.go_col)
+        }
+    }
+    return fmt.tprintf("%s(%d:%d)", loc.file_path, loc.line, loc.column)
+}
+
+// assertion_failure replaces the default panic/assert handler so the
+// failing Go statement is reported instead of the generated line.
+assertion_failure :: proc(prefix, message: string, loc: runtime.Source_Code_Location) -> ! {
+    if message != "" {
+        fmt.eprintf("%s %s: %s\n", source_pos(loc), prefix, message)
+    } else {
+        fmt.eprintf("%s %s\n", source_pos(loc), prefix)
+    }
+    if _det.enabled {
+        fmt.eprintf("replay this schedule with GOLDEN_SEED=%d\n", _det.seed)
+    }
+    runtime.trap()
 }
\ No newline at end of file
@@ -0,0 +1,110 @@
+// --- golden/transpiler/api.go ---
+
+package transpiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+)
+
+// ── Library API ──────────────────────────────────────────────────────────────
+//
+// For tools that embed golden rather than run the CLI:
+//
+//	t := transpiler.New(transpiler.Config{Options: transpiler.Options{Memory: transpiler.MemStrict}})
+//	res, err := t.Transpile(ctx, []*transpiler.Package{{Path: dir, Fset: fset, Files: files}})
+//
+// A Transpiler only holds its Config. Each package is translated with
+// its own Resolver, so one Transpiler can serve any number of goroutines.
+
+// Config configures a Transpiler.
+type Config struct {
+	// Options tunes the generated program.
+	Options
+}
+
+// Package is one Go package to translate. Its files are merged in file
+// name order, as the CLI merges a directory.
+type Package struct {
+	// Path names the package in the Result: an import path or a directory.
+	Path string
+	// Fset positions Files. Without it the output has no source map and
+	// diagnostics have no positions.
+	Fset  *token.FileSet
+	Files []*ast.File
+}
+
+// File is one generated Odin file.
+type File struct {
+	Package   string // Path of the Package it was generated from
+	Name      string // file name within the package's output directory
+	Code      string
+	SourceMap SourceMap
+}
+
+// Result is what Transpile generated.
+type Result struct {
+	Files []File
+	// Diagnostics of every package, in package order. Errors among them
+	// mean some of the output is placeholders; see HasErrors.
+	Diagnostics []Diagnostic
+}
+
+// Transpiler turns Go packages into Odin.
+type Transpiler struct {
+	cfg Config
+}
+
+// New returns a Transpiler for cfg.
+func New(cfg Config) *Transpiler {
+	return &Transpiler{cfg: cfg}
+}
+
+// Transpile translates pkgs, each into one main.odin. Code that can't be
+// translated doesn't fail it; it is reported in Result.Diagnostics. The
+// error is for a done ctx and for input that can't be translated at all.
+func (t *Transpiler) Transpile(ctx context.Context, pkgs []*Package) (*Result, error) {
+	result := &Result{}
+	for _, pkg := range pkgs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		file, diags, err := t.transpilePackage(pkg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pkg.Path, err)
+		}
+		result.Files = append(result.Files, file)
+		result.Diagnostics = append(result.Diagnostics, diags...)
+	}
+	return result, nil
+}
+
+func (t *Transpiler) transpilePackage(pkg *Package) (file File, diags []Diagnostic, err error) {
+	if len(pkg.Files) == 0 {
+		return File{}, nil, errors.New("no Go files")
+	}
+	// Go code the translator has never seen may still crash it
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("translator crashed: %v", r)
+		}
+	}()
+
+	files := append([]*ast.File(nil), pkg.Files...)
+	if pkg.Fset != nil {
+		sort.SliceStable(files, func(i, j int) bool {
+			return pkg.Fset.Position(files[i].Pos()).Filename < pkg.Fset.Position(files[j].Pos()).Filename
+		})
+	}
+	merged := &ast.File{Name: &ast.Ident{Name: files[0].Name.Name}}
+	for _, f := range files {
+		merged.Decls = append(merged.Decls, f.Decls...)
+	}
+
+	code, sm, diags := Translate(pkg.Fset, merged, t.cfg.Options)
+	return File{Package: pkg.Path, Name: "main.odin", Code: code, SourceMap: sm}, diags, nil
+}
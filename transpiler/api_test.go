@@ -0,0 +1,166 @@
+// --- golden/transpiler/api_test.go ---
+
+package transpiler
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// transpileSource translates one Go file through the library API and
+// fails the test on any error diagnostic.
+func transpileSource(t *testing.T, src string) string {
+	t.Helper()
+	code, diags := transpileWith(t, Options{}, src)
+	for _, d := range diags {
+		if d.Severity == Error {
+			t.Errorf("diagnostic: %s", d)
+		}
+	}
+	return code
+}
+
+// transpileWith translates one Go file, named main.go, with opts.
+func transpileWith(t *testing.T, opts Options, src string) (string, []Diagnostic) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := New(Config{Options: opts}).Transpile(context.Background(), []*Package{{Path: "main", Fset: fset, Files: []*ast.File{f}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.Files[0].Code, res.Diagnostics
+}
+
+// wantLines checks that each of lines is a line of code, ignoring
+// indentation, and that they appear in the order given.
+func wantLines(t *testing.T, code string, lines ...string) {
+	t.Helper()
+	got := strings.Split(code, "\n")
+	i := 0
+	for _, want := range lines {
+		for i < len(got) && strings.TrimSpace(got[i]) != want {
+			i++
+		}
+		if i == len(got) {
+			t.Fatalf("missing line (or out of order): %s\n\n%s", want, code)
+		}
+		i++
+	}
+}
+
+// rejectLines checks that none of lines is a line of code.
+func rejectLines(t *testing.T, code string, lines ...string) {
+	t.Helper()
+	for _, l := range strings.Split(code, "\n") {
+		for _, bad := range lines {
+			if strings.TrimSpace(l) == bad {
+				t.Errorf("unexpected line: %s\n\n%s", bad, code)
+			}
+		}
+	}
+}
+
+// reproSource has the map-ordered parts of a translation: closure
+// captures, struct fields, imports and several declarations.
+const reproSource = `package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+type Point struct{ X, Y, Z int }
+
+func main() {
+	var wg sync.WaitGroup
+	a, b, c := 1, "two", 3.0
+	d := Point{X: 1}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fmt.Println(a, b, c, d, b+"!")
+	}()
+	wg.Wait()
+}
+`
+
+func TestOutputIsReproducible(t *testing.T) {
+	first := transpileSource(t, reproSource)
+	for i := 0; i < 20; i++ {
+		if again, _ := transpileWith(t, Options{}, reproSource); again != first {
+			t.Fatalf("translation %d differs from the first", i+1)
+		}
+	}
+	wantLines(t, first,
+		"a: int,",
+		"b: string,",
+		"c: f64,",
+		"d: Point,",
+		"wg: ^golden.WaitGroup,",
+	)
+}
+
+func TestTranspilerIsShareable(t *testing.T) {
+	want := transpileSource(t, reproSource)
+	tr := New(Config{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "main.go", reproSource, parser.ParseComments)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res, err := tr.Transpile(context.Background(), []*Package{{Path: "main", Fset: fset, Files: []*ast.File{f}}})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if res.Files[0].Code != want {
+				t.Error("concurrent translation differs")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTranspileMergesFilesInNameOrder(t *testing.T) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, src := range []struct{ name, code string }{
+		{"b.go", "package main\n\nfunc b() {}\n"},
+		{"a.go", "package main\n\nfunc a() {}\n\nfunc main() {}\n"},
+	} {
+		f, err := parser.ParseFile(fset, src.name, src.code, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	res, err := New(Config{}).Transpile(context.Background(), []*Package{{Path: "pkg", Fset: fset, Files: files}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Files) != 1 || res.Files[0].Package != "pkg" || res.Files[0].Name != "main.odin" {
+		t.Fatalf("files = %+v", res.Files)
+	}
+	wantLines(t, res.Files[0].Code, "a :: proc() {", "main :: proc() {", "b :: proc() {")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := New(Config{}).Transpile(ctx, []*Package{{Path: "pkg", Fset: fset, Files: files}}); err != context.Canceled {
+		t.Errorf("Transpile with a done context = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,261 @@
+// --- golden/transpiler/context.go ---
+
+package transpiler
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/v4rm4n/golden/transpiler/internal/odin"
+)
+
+// ── context ──────────────────────────────────────────────────────────────────
+//
+// Contexts are ARC nodes in the runtime (golden.Context). The variable a
+// With* call is assigned to owns the node and releases it at scope end;
+// everywhere else — parameters, struct fields — a context.Context is the
+// borrowed ^golden.Ctx_Node, which callArgs passes for ARC owners to
+// functions and methods alike.
+//
+//	ctx, cancel := context.WithTimeout(parent, d)
+//	→ ctx, cancel := golden.with_timeout(parent, d)
+//	  defer golden.arc_release(&ctx)
+//
+// Reassigning an existing context (ctx = context.WithValue(ctx, k, v))
+// parks the new node in a fresh owner so the old one stays valid for the
+// child that refers to it.
+//
+// Done() is the node's runtime channel, closed on cancellation, so it
+// works both as `<-ctx.Done()` and as a select case next to other
+// channels and timers (see select.go).
+
+const (
+	ctxNodeType    = "golden.Ctx_Node"
+	cancelFuncType = "golden.Cancel_Func"
+)
+
+func mapContextType(name string) string {
+	switch name {
+	case "Context":
+		return "^" + ctxNodeType
+	case "CancelFunc":
+		return cancelFuncType
+	}
+	return "context." + name
+}
+
+// contextFunc returns the context function call invokes, if any.
+func contextFunc(call *ast.CallExpr, res *Resolver) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok || res.Imports[pkg.Name] != "context" {
+		return "", false
+	}
+	return sel.Sel.Name, true
+}
+
+// translateContextFunc handles package-level context calls.
+func translateContextFunc(name string, call *ast.CallExpr, res *Resolver) (odin.Expr, bool) {
+	switch name {
+	case "Background", "TODO":
+		return odin.NewCall("golden.context_background"), true
+	case "WithCancel", "WithTimeout", "WithDeadline", "WithValue":
+		return odin.NewCall("golden."+toSnakeCase(name), callArgs(call, res)...), true
+	}
+	return nil, false
+}
+
+// translateContextSelector maps context.Canceled and context.DeadlineExceeded.
+func translateContextSelector(e *ast.SelectorExpr, res *Resolver) (odin.Expr, bool) {
+	pkg, ok := e.X.(*ast.Ident)
+	if !ok || res.Imports[pkg.Name] != "context" {
+		return nil, false
+	}
+	switch e.Sel.Name {
+	case "Canceled":
+		return odin.NewIdent("golden.context_canceled"), true
+	case "DeadlineExceeded":
+		return odin.NewIdent("golden.context_deadline_exceeded"), true
+	}
+	return nil, false
+}
+
+// ctxPtr is the borrowed node pointer for a context expression.
+func ctxPtr(x ast.Expr, res *Resolver) odin.Expr {
+	if name, ok := isArcOwner(x, res); ok {
+		return odin.FieldOf(odin.NewIdent(name), "data")
+	}
+	return translateExpr(x, res)
+}
+
+// translateContextMethod handles Done, Err, Deadline and Value.
+func translateContextMethod(sel *ast.SelectorExpr, call *ast.CallExpr, res *Resolver) (odin.Expr, bool) {
+	if recvType(sel.X, res) != ctxNodeType {
+		return nil, false
+	}
+	p := ctxPtr(sel.X, res)
+	switch sel.Sel.Name {
+	case "Done", "Err", "Deadline":
+		return odin.NewCall("golden.context_"+strings.ToLower(sel.Sel.Name), p), true
+	case "Value":
+		return odin.NewCall("golden.context_value", append([]odin.Expr{p}, callArgs(call, res)...)...), true
+	}
+	return nil, false
+}
+
+// translateContextAssert turns ctx.Value(k).(T) into a typed lookup.
+func translateContextAssert(e *ast.TypeAssertExpr, res *Resolver) (odin.Expr, bool) {
+	call, ok := e.X.(*ast.CallExpr)
+	if !ok || e.Type == nil {
+		return nil, false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Value" || recvType(sel.X, res) != ctxNodeType {
+		return nil, false
+	}
+	args := append([]odin.Expr{ctxPtr(sel.X, res)}, callArgs(call, res)...)
+	args = append(args, &odin.TypeExpr{Type: mapType(e.Type, res)})
+	return odin.NewCall("golden.context_value_as", args...), true
+}
+
+// isCancelCall reports whether call invokes a CancelFunc variable or field.
+func isCancelCall(call *ast.CallExpr, res *Resolver) bool {
+	switch f := call.Fun.(type) {
+	case *ast.Ident:
+		sym, ok := res.Lookup(f.Name)
+		return ok && sym.GoType == cancelFuncType
+	case *ast.SelectorExpr:
+		return res.structFields[exprStructType(f.X, res)][f.Sel.Name] == cancelFuncType
+	}
+	return false
+}
+
+// isContextErr reports whether expr is ctx.Err(); its result is a static
+// string, not an owned error.
+func isContextErr(expr ast.Expr, res *Resolver) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Err" && recvType(sel.X, res) == ctxNodeType
+}
+
+// contextCallType is the Odin type of a context call's result.
+func contextCallType(call *ast.CallExpr, res *Resolver) string {
+	if name, ok := contextFunc(call, res); ok {
+		switch name {
+		case "Background", "TODO":
+			return "^" + ctxNodeType
+		}
+		return ""
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && recvType(sel.X, res) == ctxNodeType {
+		switch sel.Sel.Name {
+		case "Done":
+			return "^golden.Channel(golden.Empty)"
+		case "Err":
+			return "cstring"
+		}
+	}
+	return ""
+}
+
+// translateContextAssign emits `ctx[, cancel] := context.WithX(...)` and
+// makes the context variable the node's owner.
+func translateContextAssign(s *ast.AssignStmt, res *Resolver) ([]odin.Stmt, bool) {
+	if len(s.Rhs) != 1 {
+		return nil, false
+	}
+	call, ok := s.Rhs[0].(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+	name, ok := contextFunc(call, res)
+	if !ok {
+		return nil, false
+	}
+	switch name {
+	case "WithCancel", "WithTimeout", "WithDeadline":
+		if len(s.Lhs) != 2 {
+			return nil, false
+		}
+	case "WithValue":
+		if len(s.Lhs) != 1 {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	ctxIdent, ok := s.Lhs[0].(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+	rhs, _ := translateContextFunc(name, call, res)
+	fresh := isNewContextOwner(ctxIdent, s, res)
+
+	// The cancel func, declared ahead when the statement can't declare it
+	var cancel odin.Expr
+	var out []odin.Stmt
+	if len(s.Lhs) == 2 {
+		cancel = translateExpr(s.Lhs[1], res)
+		if ident, ok := s.Lhs[1].(*ast.Ident); ok && ident.Name != "_" {
+			if _, declared := res.Current.Symbols[ident.Name]; s.Tok == token.DEFINE && !declared {
+				res.Define(ident.Name, &Symbol{Name: ident.Name, GoType: cancelFuncType})
+				if !fresh {
+					out = append(out, &odin.VarDecl{Name: ident.Name, Type: cancelFuncType})
+				}
+			}
+		}
+	}
+	assign := func(owner odin.Expr, op string) *odin.Assign {
+		a := &odin.Assign{Lhs: []odin.Expr{owner}, Op: op, Rhs: []odin.Expr{rhs}}
+		if cancel != nil {
+			a.Lhs = append(a.Lhs, cancel)
+		}
+		return a
+	}
+
+	ctx := odin.NewIdent(ctxIdent.Name)
+	if fresh {
+		res.Define(ctxIdent.Name, &Symbol{Name: ctxIdent.Name, GoType: ctxNodeType, Strategy: AllocARC})
+		out = append(out, assign(ctx, ":="))
+		return append(out, arcReleaseDefer(ctxIdent.Name, s, res)...), true
+	}
+
+	ownerName := posName("_context", s.Pos(), res)
+	owner := odin.NewIdent(ownerName)
+	out = append(out,
+		&odin.VarDecl{Name: ownerName, Type: "golden.Context"},
+		assign(owner, "="),
+	)
+	if ctxIdent.Name == "_" {
+		return append(out, odin.DeferCall("golden.arc_release", odin.AddrOf(owner))), true
+	}
+	if sym, ok := res.Lookup(ctxIdent.Name); ok && sym.Strategy == AllocARC {
+		// ctx already owns a node: the child keeps that one alive now
+		return append(out,
+			odin.CallStmt("golden.arc_release", odin.AddrOf(ctx)),
+			odin.NewAssign(ctx, "=", odin.NewCall("golden.arc_move", odin.AddrOf(owner))),
+		), true
+	}
+	out = append(out, odin.DeferCall("golden.arc_release", odin.AddrOf(owner)))
+	if sym, ok := res.Lookup(ctxIdent.Name); ok && sym.IsParam {
+		res.Define(ctxIdent.Name, &Symbol{Name: ctxIdent.Name, GoType: "^" + ctxNodeType})
+		return append(out, odin.NewAssign(ctx, ":=", odin.FieldOf(owner, "data"))), true
+	}
+	return append(out, odin.NewAssign(ctx, "=", odin.FieldOf(owner, "data"))), true
+}
+
+// isNewContextOwner reports whether the statement declares ctx afresh.
+func isNewContextOwner(ctx *ast.Ident, s *ast.AssignStmt, res *Resolver) bool {
+	if s.Tok != token.DEFINE || ctx.Name == "_" {
+		return false
+	}
+	_, declared := res.Current.Symbols[ctx.Name]
+	return !declared
+}
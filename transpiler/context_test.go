@@ -0,0 +1,52 @@
+// --- golden/transpiler/context_test.go ---
+
+package transpiler
+
+import "testing"
+
+func TestContextBorrowedByCalls(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type ctxKey string
+
+type Job struct{ ID int }
+
+func (j *Job) Do(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func run(ctx context.Context) {
+	fmt.Println(ctx.Value(ctxKey("user")).(string))
+}
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	ctx = context.WithValue(ctx, ctxKey("user"), "bob")
+	run(ctx)
+	j := Job{ID: 1}
+	fmt.Println(j.Do(ctx))
+}
+`)
+	wantLines(t, code,
+		"Job_Do :: proc(j: ^Job, ctx: ^golden.Ctx_Node) -> cstring {",
+		"golden.chan_recv(golden.context_done(ctx))",
+		"return golden.context_err(ctx)",
+		"run :: proc(ctx: ^golden.Ctx_Node) {",
+		"fmt.println(golden.context_value_as(ctx, ctxKey(\"user\"), string))",
+		"ctx, cancel := golden.with_timeout(golden.context_background(), time.Millisecond)",
+		"defer golden.arc_release(&ctx)",
+		"defer golden.cancel_call(cancel)",
+		"_context_main_25_2 = golden.with_value(ctx.data, ctxKey(\"user\"), \"bob\")",
+		"ctx = golden.arc_move(&_context_main_25_2)",
+		"run(ctx.data)",
+		"fmt.println(Job_Do(&j, ctx.data))",
+	)
+}
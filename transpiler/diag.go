@@ -0,0 +1,163 @@
+// --- golden/transpiler/diag.go ---
+
+package transpiler
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// ── Diagnostics ──────────────────────────────────────────────────────────────
+//
+// Anything the transpiler can't translate faithfully is reported against
+// its Go position instead of passing silently. Errors mean the output is
+// wrong or won't build; warnings mean it builds but loses something (a
+// type degraded to rawptr). The output still gets a placeholder so the
+// rest can be inspected.
+
+// Severity grades a Diagnostic.
+type Severity int
+
+const (
+	Warning Severity = iota
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Category groups diagnostics by the kind of Go construct involved.
+type Category string
+
+const (
+	CatStatement   Category = "statement"
+	CatExpression  Category = "expression"
+	CatType        Category = "type"
+	CatDeclaration Category = "declaration"
+	CatStdlib      Category = "stdlib" // calls into packages with no shim
+	CatGoroutine   Category = "goroutine"
+)
+
+// Diagnostic is one problem found while translating.
+type Diagnostic struct {
+	Pos      token.Position
+	Severity Severity
+	Category Category
+	// Construct names what wasn't translated, for tallies:
+	// "select statement", "strings.ToUpper", "func type".
+	Construct string
+	Msg       string
+}
+
+// String formats d like go vet: "file.go:12:3: message".
+func (d Diagnostic) String() string {
+	msg := d.Msg
+	if d.Severity == Warning {
+		msg = "warning: " + msg
+	}
+	if !d.Pos.IsValid() {
+		return msg
+	}
+	return fmt.Sprintf("%s: %s", d.Pos, msg)
+}
+
+// HasErrors reports whether any of diags is an error.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// diagSink collects one translation's diagnostics.
+type diagSink struct {
+	fset  *token.FileSet
+	seen  map[string]bool
+	diags []Diagnostic
+}
+
+func newDiagSink(fset *token.FileSet) *diagSink {
+	return &diagSink{fset: fset, seen: make(map[string]bool)}
+}
+
+// report records a diagnostic at pos once; the census and translation
+// passes both visit some nodes.
+func (r *Resolver) report(pos token.Pos, sev Severity, cat Category, construct, format string, args ...any) {
+	d := Diagnostic{Severity: sev, Category: cat, Construct: construct, Msg: fmt.Sprintf(format, args...)}
+	if r.diags.fset != nil && pos.IsValid() {
+		d.Pos = r.diags.fset.Position(pos)
+	}
+	key := fmt.Sprintf("%d|%s", pos, d.Msg)
+	if r.diags.seen[key] {
+		return
+	}
+	r.diags.seen[key] = true
+	r.diags.diags = append(r.diags.diags, d)
+}
+
+// sorted returns what was reported, in source order.
+func (s *diagSink) sorted() []Diagnostic {
+	out := s.diags
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i].Pos, out[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return out
+}
+
+// nodeKind names a node for a message: *ast.SelectStmt → "select
+// statement".
+func nodeKind(n ast.Node) string {
+	switch n := n.(type) {
+	case *ast.SelectStmt:
+		return "select statement"
+	case *ast.TypeSwitchStmt:
+		return "type switch"
+	case *ast.LabeledStmt:
+		return "labeled statement"
+	case *ast.BranchStmt:
+		return n.Tok.String() + " statement"
+	case *ast.GoStmt:
+		return "go statement"
+	case *ast.DeferStmt:
+		return "defer statement"
+	case *ast.FuncType:
+		return "func type"
+	case *ast.InterfaceType:
+		return "interface type"
+	case *ast.StructType:
+		return "struct type"
+	case *ast.Ellipsis:
+		return "variadic type"
+	case *ast.FuncLit:
+		return "func literal"
+	case *ast.ParenExpr:
+		return "parenthesized expression"
+	case *ast.IndexListExpr:
+		return "generic instantiation"
+	case *ast.KeyValueExpr:
+		return "key-value expression"
+	case *ast.CallExpr:
+		return "call"
+	case *ast.SelectorExpr:
+		return "selector"
+	case *ast.Ident:
+		return "identifier"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+}
@@ -0,0 +1,32 @@
+// --- golden/transpiler/diag_test.go ---
+
+package transpiler
+
+import "testing"
+
+func TestDiagnosticsArePositioned(t *testing.T) {
+	code, diags := transpileWith(t, Options{}, `package main
+
+func main() {
+	var x any = 1
+	switch x.(type) {
+	case int:
+	}
+}
+`)
+	if !HasErrors(diags) {
+		t.Fatalf("no error for a type switch: %v", diags)
+	}
+	for _, d := range diags {
+		if d.Severity == Error {
+			if got := d.String(); got != "main.go:5:2: unsupported type switch" {
+				t.Errorf("diagnostic = %q", got)
+			}
+			if d.Category != CatStatement {
+				t.Errorf("category = %s, want %s", d.Category, CatStatement)
+			}
+		}
+	}
+	// The output keeps a placeholder rather than a TODO
+	wantLines(t, code, "// unsupported type switch")
+}
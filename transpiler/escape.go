@@ -1,4 +1,4 @@
-// --- golden/internal/transpiler/escape.go ---
+// --- golden/transpiler/escape.go ---
 
 package transpiler
 
@@ -14,7 +14,7 @@ import (
 //
 // A variable ESCAPES if it is:
 //   1. Returned from the function
-//   2. Passed to a goroutine (go stmt)
+//   2. Passed to a goroutine (go stmt) that isn't joined before return
 //   3. Assigned into a field of another struct (stored)
 //   4. Assigned to a package-level var (global store)
 //
@@ -34,11 +34,79 @@ func AnalyzeFunc(body *ast.BlockStmt) EscapeSet {
 	// names to watch.
 	arcVars := collectArcDecls(body.List)
 
+	// Goroutines joined by a later wg.Wait() live strictly inside this
+	// function, so their captures stay local (see isJoinedGo).
+	joined := make(map[*ast.GoStmt]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		if g, ok := n.(*ast.GoStmt); ok && isJoinedGo(g, body) {
+			joined[g] = true
+		}
+		return true
+	})
+
+	// Aliases (v := u) are watched too; if one escapes, so does its root.
+	aliases := collectAliases(body, arcVars)
+	for alias := range aliases {
+		arcVars[alias] = true
+	}
+
 	// Second pass: walk all statements looking for escape sites.
 	for _, stmt := range body.List {
-		walkForEscapes(stmt, arcVars, esc)
+		walkForEscapes(stmt, arcVars, esc, joined)
 	}
 
+	for alias, root := range aliases {
+		if esc[alias] {
+			esc[root] = true
+		}
+	}
+	return esc
+}
+
+// collectAliases maps every plain copy of a watched name (v := u, w := v)
+// to the original declaration it refers to.
+func collectAliases(body *ast.BlockStmt, vars map[string]bool) map[string]string {
+	aliases := make(map[string]string)
+	root := func(name string) (string, bool) {
+		if vars[name] {
+			return name, true
+		}
+		r, ok := aliases[name]
+		return r, ok
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, rhs := range assign.Rhs {
+			src, ok := rhs.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			dst, ok := assign.Lhs[i].(*ast.Ident)
+			if !ok || vars[dst.Name] {
+				continue
+			}
+			if r, ok := root(src.Name); ok {
+				aliases[dst.Name] = r
+			}
+		}
+		return true
+	})
+	return aliases
+}
+
+// AnalyzeVars runs the same escape walk for an explicit set of names
+// (slices, maps and heap strings collected by the function pre-pass).
+func AnalyzeVars(body *ast.BlockStmt, vars map[string]bool) EscapeSet {
+	esc := make(EscapeSet)
+	if body == nil || len(vars) == 0 {
+		return esc
+	}
+	for _, stmt := range body.List {
+		walkForEscapes(stmt, vars, esc, nil)
+	}
 	return esc
 }
 
@@ -67,7 +135,7 @@ func collectArcDecls(stmts []ast.Stmt) map[string]bool {
 }
 
 // walkForEscapes checks a statement for all escape patterns.
-func walkForEscapes(stmt ast.Stmt, arcVars map[string]bool, esc EscapeSet) {
+func walkForEscapes(stmt ast.Stmt, arcVars map[string]bool, esc EscapeSet, joined map[*ast.GoStmt]bool) {
 	switch s := stmt.(type) {
 
 	// return x  →  x escapes
@@ -78,6 +146,9 @@ func walkForEscapes(stmt ast.Stmt, arcVars map[string]bool, esc EscapeSet) {
 
 	// go func() { use(x) }  →  x escapes
 	case *ast.GoStmt:
+		if joined[s] {
+			return
+		}
 		ast.Inspect(s.Call, func(n ast.Node) bool {
 			if ident, ok := n.(*ast.Ident); ok && arcVars[ident.Name] {
 				esc[ident.Name] = true
@@ -99,17 +170,17 @@ func walkForEscapes(stmt ast.Stmt, arcVars map[string]bool, esc EscapeSet) {
 	// Recurse into blocks, if/else, for bodies
 	case *ast.BlockStmt:
 		for _, inner := range s.List {
-			walkForEscapes(inner, arcVars, esc)
+			walkForEscapes(inner, arcVars, esc, joined)
 		}
 	case *ast.IfStmt:
-		walkForEscapes(s.Body, arcVars, esc)
+		walkForEscapes(s.Body, arcVars, esc, joined)
 		if s.Else != nil {
-			walkForEscapes(s.Else, arcVars, esc)
+			walkForEscapes(s.Else, arcVars, esc, joined)
 		}
 	case *ast.ForStmt:
-		walkForEscapes(s.Body, arcVars, esc)
+		walkForEscapes(s.Body, arcVars, esc, joined)
 	case *ast.RangeStmt:
-		walkForEscapes(s.Body, arcVars, esc)
+		walkForEscapes(s.Body, arcVars, esc, joined)
 	}
 }
 
@@ -122,3 +193,30 @@ func markEscapingIdents(expr ast.Expr, arcVars map[string]bool, esc EscapeSet) {
 		return true
 	})
 }
+
+// isStoredVar reports whether name is assigned into a struct field
+// anywhere in scopeStmt — the struct takes over ownership.
+func isStoredVar(name string, scopeStmt ast.Node) bool {
+	if scopeStmt == nil {
+		return false
+	}
+	stored := false
+	ast.Inspect(scopeStmt, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok {
+			return !stored
+		}
+		for _, lhs := range assign.Lhs {
+			if _, ok := lhs.(*ast.SelectorExpr); !ok {
+				continue
+			}
+			for _, rhs := range assign.Rhs {
+				if ident, ok := rhs.(*ast.Ident); ok && ident.Name == name {
+					stored = true
+				}
+			}
+		}
+		return !stored
+	})
+	return stored
+}
@@ -0,0 +1,408 @@
+// --- golden/transpiler/gostmt.go ---
+
+package transpiler
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"github.com/v4rm4n/golden/transpiler/internal/odin"
+)
+
+// ── go Statements ────────────────────────────────────────────────────────────
+//
+// Every goroutine is spawned through a func literal. Other forms are
+// rewritten into one before translation:
+//
+//	go worker(i*2, ch)   →  go func(_a0 int) { worker(_a0, ch) }(i*2)
+//	go s.Run(x)          →  go func(_recv ^Server) { _recv.Run(x) }(&s)
+//
+// Plain identifiers stay in the call and are captured like any closure
+// variable (copied, or handed off when ARC). Everything else — computed
+// arguments and pointer receivers — becomes a parameter, so it is
+// evaluated at the go statement exactly as Go does.
+
+// goParam is a goroutine parameter stored in the closure context.
+type goParam struct {
+	Name string
+	Type string    // Odin type
+	Arg  odin.Expr // evaluated at the go statement
+}
+
+// registerFuncParams records fd's parameter types; variadic tails are left out.
+func registerFuncParams(fd *ast.FuncDecl, res *Resolver) {
+	var types []string
+	for _, field := range fd.Type.Params.List {
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			break
+		}
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, mapType(field.Type, res))
+		}
+	}
+	res.funcParamTypes[fd.Name.Name] = types
+}
+
+// goClosure returns the func literal a go statement runs and the
+// parameters to evaluate up front.
+func goClosure(call *ast.CallExpr, res *Resolver) (*ast.FuncLit, []goParam, bool) {
+	if fn, ok := call.Fun.(*ast.FuncLit); ok {
+		params, ok := funcLitParams(fn, call.Args, res)
+		return fn, params, ok
+	}
+
+	var params []goParam
+	fun := call.Fun
+	name := ""
+	switch f := call.Fun.(type) {
+	case *ast.Ident:
+		name = f.Name
+	case *ast.SelectorExpr:
+		name = f.Sel.Name
+		if pkg, ok := f.X.(*ast.Ident); ok && res.Imports[pkg.Name] != "" {
+			break
+		}
+		recv, param, ok := goReceiver(f, res)
+		if !ok {
+			return nil, nil, false
+		}
+		if param != nil {
+			params = append(params, *param)
+		}
+		fun = &ast.SelectorExpr{X: recv, Sel: f.Sel}
+	default:
+		return nil, nil, false
+	}
+
+	args := make([]ast.Expr, len(call.Args))
+	for i, arg := range call.Args {
+		if isCapturable(arg, res) {
+			args[i] = arg
+			continue
+		}
+		t, ok := goArgType(name, i, arg, res)
+		if !ok {
+			return nil, nil, false
+		}
+		p := goParam{Name: fmt.Sprintf("_a%d", i), Type: t, Arg: translateExpr(arg, res)}
+		params = append(params, p)
+		args[i] = ast.NewIdent(p.Name)
+	}
+
+	body := &ast.CallExpr{Fun: fun, Args: args, Ellipsis: call.Ellipsis}
+	lit := &ast.FuncLit{
+		Type: &ast.FuncType{Params: &ast.FieldList{}},
+		Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ExprStmt{X: body}}},
+	}
+	return lit, params, true
+}
+
+// funcLitParams binds a literal's parameters to the call's arguments.
+func funcLitParams(fn *ast.FuncLit, args []ast.Expr, res *Resolver) ([]goParam, bool) {
+	var params []goParam
+	i := 0
+	for _, field := range fn.Type.Params.List {
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			return nil, false
+		}
+		for _, name := range field.Names {
+			if i >= len(args) {
+				return nil, false
+			}
+			if name.Name != "_" {
+				params = append(params, goParam{Name: name.Name, Type: mapType(field.Type, res), Arg: translateExpr(args[i], res)})
+			}
+			i++
+		}
+	}
+	return params, true
+}
+
+// goReceiver decides how a method call's receiver reaches the goroutine.
+// A nil param means the receiver is captured as is.
+func goReceiver(sel *ast.SelectorExpr, res *Resolver) (ast.Expr, *goParam, bool) {
+	structType := exprStructType(sel.X, res)
+	if structType == "" {
+		return nil, nil, false
+	}
+	isPtr := res.methodIsPointer[sel.Sel.Name]
+
+	if ident, ok := sel.X.(*ast.Ident); ok {
+		sym, ok := res.Lookup(ident.Name)
+		if !ok || sym.Strategy == AllocARC || !isPtr || isPointerType(sym.GoType) {
+			return sel.X, nil, true
+		}
+		// A pointer method on a value: share the variable, not a copy
+		return ast.NewIdent("_recv"), &goParam{Name: "_recv", Type: "^" + structType, Arg: odin.AddrOf(odin.NewIdent(ident.Name))}, true
+	}
+
+	recv := translateExpr(sel.X, res)
+	if inner, ok := sel.X.(*ast.SelectorExpr); ok {
+		t := borrowedType(res.structFields[exprStructType(inner.X, res)][inner.Sel.Name])
+		if isPointerType(t) {
+			return ast.NewIdent("_recv"), &goParam{Name: "_recv", Type: t, Arg: recv}, true
+		}
+	}
+	if isPtr {
+		return ast.NewIdent("_recv"), &goParam{Name: "_recv", Type: "^" + structType, Arg: odin.AddrOf(recv)}, true
+	}
+	return ast.NewIdent("_recv"), &goParam{Name: "_recv", Type: structType, Arg: recv}, true
+}
+
+func isPointerType(t string) bool {
+	return len(t) > 0 && t[0] == '^'
+}
+
+// isCapturable reports whether an argument can be left to closure capture:
+// identifiers and literals read the same at spawn time and in the goroutine.
+func isCapturable(arg ast.Expr, res *Resolver) bool {
+	switch a := arg.(type) {
+	case *ast.Ident, *ast.BasicLit:
+		return true
+	case *ast.UnaryExpr:
+		// &wg, &mu: sync primitives are captured by reference already
+		if ident, ok := a.X.(*ast.Ident); ok && a.Op == token.AND {
+			sym, ok := res.Lookup(ident.Name)
+			return ok && isSharedSyncType(sym.GoType)
+		}
+	}
+	return false
+}
+
+// goArgType resolves the Odin type of argument i of callee name.
+func goArgType(name string, i int, arg ast.Expr, res *Resolver) (string, bool) {
+	if types, ok := res.funcParamTypes[name]; ok && i < len(types) {
+		return types[i], true
+	}
+	if u, ok := arg.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		if ident, ok := u.X.(*ast.Ident); ok {
+			if sym, ok := res.Lookup(ident.Name); ok {
+				return "^" + sym.GoType, true
+			}
+		}
+	}
+	if isStringExpr(arg, res) {
+		return "string", true
+	}
+	return "", false
+}
+
+// ── Captures ─────────────────────────────────────────────────────────────────
+//
+// A goroutine captures the variables its literal refers to but doesn't
+// declare. Names are resolved the way the Go compiler does: a declaration
+// in the literal only covers the rest of its block, so in
+//
+//	go func() { fmt.Println(x); x := 7; _ = x }()
+//
+// the first x is captured and the second is a local of the goroutine.
+
+// freeIdents returns the identifiers in fn that refer to a declaration
+// outside it.
+func freeIdents(fn *ast.FuncLit) map[*ast.Ident]bool {
+	b := &binder{free: make(map[*ast.Ident]bool)}
+	b.funcLit(fn)
+	return b.free
+}
+
+// binder walks a function body keeping the names declared in each scope.
+type binder struct {
+	scopes []map[string]bool
+	free   map[*ast.Ident]bool
+}
+
+func (b *binder) push() { b.scopes = append(b.scopes, make(map[string]bool)) }
+func (b *binder) pop()  { b.scopes = b.scopes[:len(b.scopes)-1] }
+
+func (b *binder) declare(idents ...*ast.Ident) {
+	for _, id := range idents {
+		if id != nil {
+			b.scopes[len(b.scopes)-1][id.Name] = true
+		}
+	}
+}
+
+func (b *binder) use(id *ast.Ident) {
+	for i := len(b.scopes) - 1; i >= 0; i-- {
+		if b.scopes[i][id.Name] {
+			return
+		}
+	}
+	b.free[id] = true
+}
+
+func (b *binder) funcLit(fn *ast.FuncLit) {
+	b.push()
+	defer b.pop()
+	for _, fl := range []*ast.FieldList{fn.Type.Params, fn.Type.Results} {
+		if fl == nil {
+			continue
+		}
+		for _, field := range fl.List {
+			b.declare(field.Names...)
+		}
+	}
+	// The body shares the parameters' scope
+	b.stmts(fn.Body.List)
+}
+
+func (b *binder) block(list []ast.Stmt) {
+	b.push()
+	defer b.pop()
+	b.stmts(list)
+}
+
+func (b *binder) stmts(list []ast.Stmt) {
+	for _, s := range list {
+		b.stmt(s)
+	}
+}
+
+func (b *binder) stmt(s ast.Stmt) {
+	switch s := s.(type) {
+	case *ast.AssignStmt:
+		b.exprs(s.Rhs)
+		if s.Tok != token.DEFINE {
+			b.exprs(s.Lhs)
+			return
+		}
+		for _, lhs := range s.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok {
+				b.declare(id)
+			}
+		}
+	case *ast.DeclStmt:
+		for _, spec := range s.Decl.(*ast.GenDecl).Specs {
+			switch sp := spec.(type) {
+			case *ast.ValueSpec:
+				b.exprs(sp.Values)
+				b.declare(sp.Names...)
+			case *ast.TypeSpec:
+				b.declare(sp.Name)
+			}
+		}
+	case *ast.BlockStmt:
+		b.block(s.List)
+	case *ast.IfStmt:
+		b.push()
+		b.stmt(s.Init)
+		b.expr(s.Cond)
+		b.block(s.Body.List)
+		b.stmt(s.Else)
+		b.pop()
+	case *ast.ForStmt:
+		b.push()
+		b.stmt(s.Init)
+		b.expr(s.Cond)
+		b.stmt(s.Post)
+		b.block(s.Body.List)
+		b.pop()
+	case *ast.RangeStmt:
+		b.expr(s.X)
+		b.push()
+		for _, v := range []ast.Expr{s.Key, s.Value} {
+			if id, ok := v.(*ast.Ident); ok && s.Tok == token.DEFINE {
+				b.declare(id)
+			} else {
+				b.expr(v)
+			}
+		}
+		b.block(s.Body.List)
+		b.pop()
+	case *ast.SwitchStmt:
+		b.push()
+		b.stmt(s.Init)
+		b.expr(s.Tag)
+		for _, c := range s.Body.List {
+			cc := c.(*ast.CaseClause)
+			b.exprs(cc.List)
+			b.block(cc.Body)
+		}
+		b.pop()
+	case *ast.TypeSwitchStmt:
+		b.push()
+		b.stmt(s.Init)
+		var bound *ast.Ident
+		if as, ok := s.Assign.(*ast.AssignStmt); ok {
+			b.exprs(as.Rhs)
+			bound, _ = as.Lhs[0].(*ast.Ident)
+		} else {
+			b.stmt(s.Assign)
+		}
+		for _, c := range s.Body.List {
+			b.push()
+			b.declare(bound)
+			b.stmts(c.(*ast.CaseClause).Body)
+			b.pop()
+		}
+		b.pop()
+	case *ast.SelectStmt:
+		for _, c := range s.Body.List {
+			cc := c.(*ast.CommClause)
+			b.push()
+			b.stmt(cc.Comm)
+			b.stmts(cc.Body)
+			b.pop()
+		}
+	case *ast.LabeledStmt:
+		b.stmt(s.Stmt)
+	case *ast.ExprStmt:
+		b.expr(s.X)
+	case *ast.SendStmt:
+		b.expr(s.Chan)
+		b.expr(s.Value)
+	case *ast.IncDecStmt:
+		b.expr(s.X)
+	case *ast.GoStmt:
+		b.expr(s.Call)
+	case *ast.DeferStmt:
+		b.expr(s.Call)
+	case *ast.ReturnStmt:
+		b.exprs(s.Results)
+	}
+}
+
+func (b *binder) exprs(list []ast.Expr) {
+	for _, e := range list {
+		b.expr(e)
+	}
+}
+
+// expr resolves the names an expression uses. Field names — after a dot
+// or as struct literal keys — aren't variables and are skipped.
+func (b *binder) expr(e ast.Expr) {
+	if e == nil {
+		return
+	}
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Ident:
+			b.use(x)
+		case *ast.FuncLit:
+			b.funcLit(x)
+			return false
+		case *ast.SelectorExpr:
+			b.expr(x.X)
+			return false
+		case *ast.CompositeLit:
+			_, isMap := x.Type.(*ast.MapType)
+			for _, elt := range x.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if _, isField := kv.Key.(*ast.Ident); !isField || isMap {
+						b.expr(kv.Key)
+					}
+					b.expr(kv.Value)
+					continue
+				}
+				b.expr(elt)
+			}
+			return false
+		}
+		return true
+	})
+}
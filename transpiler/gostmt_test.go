@@ -0,0 +1,169 @@
+// --- golden/transpiler/gostmt_test.go ---
+
+package transpiler
+
+import "testing"
+
+func TestGoCapturesBesideLocalCtx(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type Node struct{ V int }
+
+func work(ctx context.Context, n *Node, wg *sync.WaitGroup) {
+	defer wg.Done()
+}
+
+func main() {
+	var wg sync.WaitGroup
+	n := &Node{V: 1}
+	wg.Add(1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		work(ctx, n, &wg)
+	}()
+	wg.Wait()
+}
+`)
+	wantLines(t, code,
+		"_gctx := cast(^_closure_ctx_main_19_2)data",
+		"ctx, cancel := golden.with_timeout(golden.context_background(), time.Second)",
+		"work(ctx.data, _gctx.n.data, _gctx.wg)",
+		"golden.arc_release(&_gctx.n)",
+		"free(_gctx)",
+	)
+}
+
+func TestGoCaptureShadowedByLocal(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import "fmt"
+
+func main() {
+	x := 1
+	done := make(chan bool)
+	go func() {
+		fmt.Println(x)
+		x := 2
+		fmt.Println(x)
+		done <- true
+	}()
+	<-done
+}
+`)
+	wantLines(t, code,
+		"fmt.println(_gctx.x)",
+		"x := 2",
+		"fmt.println(x)",
+		"golden.chan_send(_gctx.done, true)",
+	)
+}
+
+func TestMethodArcArgs(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type Node struct{ V int }
+
+type Server struct{ Name string }
+
+func (s *Server) Run(ctx context.Context, n *Node) {
+	fmt.Println(s.Name, n.V)
+}
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &Server{Name: "a"}
+	n := &Node{V: 1}
+	s.Run(ctx, n)
+	go s.Run(ctx, n)
+}
+`)
+	wantLines(t, code,
+		"Server_Run(s.data, ctx.data, n.data)",
+		"_ctx_main_22_2.ctx = golden.arc_move(&ctx)",
+		"Server_Run(_gctx.s.data, _gctx.ctx.data, _gctx.n.data)",
+		"golden.arc_release(&_gctx.ctx)",
+	)
+}
+
+func TestGoNamedFuncAndMethod(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import "sync"
+
+type Counter struct{ n int }
+
+func (c *Counter) Inc(wg *sync.WaitGroup) {
+	defer wg.Done()
+	c.n++
+}
+
+func worker(id int, wg *sync.WaitGroup) {
+	defer wg.Done()
+}
+
+func main() {
+	var wg sync.WaitGroup
+	var c Counter
+	for i := 0; i < 3; i++ {
+		wg.Add(2)
+		go worker(i*2, &wg)
+		go c.Inc(&wg)
+	}
+	wg.Wait()
+}
+`)
+	// Computed arguments and pointer receivers are evaluated at the go
+	// statement
+	wantLines(t, code,
+		"_ctx_main_21_3.wg = &wg",
+		"_ctx_main_21_3._a0 = i * 2",
+		"worker(_gctx._a0, _gctx.wg)",
+		`golden.spawn_raw(_go_wrapper_main_21_3, _ctx_main_21_3, "main.go:21:3")`,
+		"_ctx_main_22_3._recv = &c",
+		"Counter_Inc(_gctx._recv, _gctx.wg)",
+	)
+}
+
+func TestGoroutineFrameArena(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+func main() {
+	var wg sync.WaitGroup
+	name := "x"
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		msg := "hello " + name
+		fmt.Println(msg)
+	}()
+	wg.Wait()
+}
+`)
+	// The goroutine allocates from its own arena, released as it returns
+	wantLines(t, code,
+		"_gctx := cast(^_closure_ctx_main_12_2)data",
+		"_frame := golden.frame_begin()",
+		"defer golden.frame_end(&_frame)",
+		"defer golden.wg_done(_gctx.wg)",
+		`msg := strings.concatenate({"hello ", _gctx.name}, golden.frame_allocator(&_frame))`,
+		"free(_gctx)",
+	)
+}
@@ -0,0 +1,586 @@
+// --- golden/transpiler/heap.go ---
+
+package transpiler
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/v4rm4n/golden/transpiler/internal/odin"
+)
+
+// ── Heap Values (slices, maps, strings) ──────────────────────────────────────
+//
+// Slices, maps and heap strings get the same strategy selection as &T{}:
+//   - LOCAL values are carved out of the function's frame arena and vanish
+//     with it at frame_end — no delete() needed.
+//   - ESCAPING values are OWNED: allocated on context.allocator and released
+//     by an injected `defer delete(x)`. Ownership moves out per path, as
+//     with ARC: `return x` and a store that outlives the function hand the
+//     value on with golden.take(&x), which empties x so the deferred delete
+//     frees nothing there. Reassigning an owned variable frees the value
+//     it held.
+
+// frameAllocator is the allocator of the function's frame arena.
+func frameAllocator() odin.Expr {
+	return odin.NewCall("golden.frame_allocator", odin.AddrOf(odin.NewIdent("_frame")))
+}
+
+// frameBegin opens the function's frame arena, closed when it returns.
+func frameBegin() []odin.Stmt {
+	frame := odin.NewIdent("_frame")
+	return []odin.Stmt{
+		odin.Define("_frame", odin.NewCall("golden.frame_begin")),
+		odin.DeferCall("golden.frame_end", odin.AddrOf(frame)),
+	}
+}
+
+type heapKind int
+
+const (
+	heapNone      heapKind = iota
+	heapMake               // make([]T, n) / make(map[K]V)
+	heapSliceLit           // []T{a, b, c}
+	heapSprintf            // fmt.Sprintf(...)
+	heapConcat             // a + "b"
+	heapZeroSlice          // var xs []T  (grows through append)
+	heapOwnedCall          // f() where f hands an owned value to its caller
+	heapClone              // a string copied so that its variable owns it
+)
+
+// classifyHeap reports which kind of heap allocation expr performs, if any.
+func classifyHeap(expr ast.Expr, res *Resolver) heapKind {
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		name := exprToStrBasic(e.Fun)
+		switch {
+		case name == "make" && len(e.Args) > 0:
+			switch t := e.Args[0].(type) {
+			case *ast.ArrayType:
+				if t.Len == nil {
+					return heapMake
+				}
+			case *ast.MapType:
+				return heapMake
+			}
+		case name == "fmt.Sprintf":
+			return heapSprintf
+		case res.funcReturnsOwned[name] != "":
+			return heapOwnedCall
+		}
+	case *ast.CompositeLit:
+		if t, ok := e.Type.(*ast.ArrayType); ok && t.Len == nil {
+			return heapSliceLit
+		}
+	case *ast.BinaryExpr:
+		if isRuntimeConcat(e, res) {
+			return heapConcat
+		}
+	}
+	return heapNone
+}
+
+// heapGoType returns the Odin type of a heap allocation expression.
+func heapGoType(expr ast.Expr, kind heapKind, res *Resolver) string {
+	switch kind {
+	case heapMake:
+		return mapType(expr.(*ast.CallExpr).Args[0], res)
+	case heapSliceLit:
+		return mapType(expr.(*ast.CompositeLit).Type, res)
+	case heapSprintf, heapConcat, heapClone:
+		return "string"
+	case heapOwnedCall:
+		return res.funcReturnsOwned[exprToStrBasic(expr.(*ast.CallExpr).Fun)]
+	}
+	return ""
+}
+
+// isStringExpr reports whether expr is known to produce a Go string.
+func isStringExpr(expr ast.Expr, res *Resolver) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return e.Kind == token.STRING
+	case *ast.Ident:
+		sym, ok := res.Lookup(e.Name)
+		return ok && sym.GoType == "string"
+	case *ast.ParenExpr:
+		return isStringExpr(e.X, res)
+	case *ast.BinaryExpr:
+		return e.Op == token.ADD && (isStringExpr(e.X, res) || isStringExpr(e.Y, res))
+	case *ast.CallExpr:
+		name := exprToStrBasic(e.Fun)
+		return name == "fmt.Sprintf" || res.funcReturnsOwned[name] == "string"
+	}
+	return false
+}
+
+// isRuntimeConcat reports whether e is a string concatenation that Odin
+// cannot fold at compile time (i.e. not every operand is a literal).
+func isRuntimeConcat(e *ast.BinaryExpr, res *Resolver) bool {
+	if !isStringExpr(e, res) {
+		return false
+	}
+	for _, part := range concatParts(e) {
+		if lit, ok := part.(*ast.BasicLit); !ok || lit.Kind != token.STRING {
+			return true
+		}
+	}
+	return false
+}
+
+// concatParts flattens a + b + (c + d) into [a, b, c, d].
+func concatParts(expr ast.Expr) []ast.Expr {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			return append(concatParts(e.X), concatParts(e.Y)...)
+		}
+	case *ast.ParenExpr:
+		return concatParts(e.X)
+	}
+	return []ast.Expr{expr}
+}
+
+// translateConcat emits a runtime string concatenation on alloc.
+func translateConcat(e *ast.BinaryExpr, alloc odin.Expr, res *Resolver) odin.Expr {
+	res.CoreImports["strings"] = true
+	parts := &odin.CompositeLit{}
+	for _, part := range concatParts(e) {
+		parts.Elts = append(parts.Elts, translateExpr(part, res))
+	}
+	if alloc == nil {
+		return odin.NewCall("strings.concatenate", parts)
+	}
+	return odin.NewCall("strings.concatenate", parts, alloc)
+}
+
+// heapAllocExpr emits expr so that it allocates on alloc.
+// A nil alloc means context.allocator.
+func heapAllocExpr(expr ast.Expr, kind heapKind, alloc odin.Expr, res *Resolver) odin.Expr {
+	switch kind {
+	case heapMake:
+		call := expr.(*ast.CallExpr)
+		var args []odin.Expr
+		for _, arg := range call.Args {
+			args = append(args, translateExpr(arg, res))
+		}
+		if alloc != nil {
+			args = append(args, &odin.FieldValue{Name: "allocator", Value: alloc})
+		}
+		return odin.NewCall("make", args...)
+	case heapSliceLit:
+		lit := expr.(*ast.CompositeLit)
+		elems := &odin.CompositeLit{Type: "[]" + mapType(lit.Type.(*ast.ArrayType).Elt, res)}
+		for _, elt := range lit.Elts {
+			elems.Elts = append(elems.Elts, translateExpr(elt, res))
+		}
+		if alloc != nil {
+			return odin.NewCall("golden.slice_of", elems, alloc)
+		}
+		return odin.NewCall("golden.slice_of", elems)
+	case heapSprintf:
+		args := callArgs(expr.(*ast.CallExpr), res)
+		if alloc != nil {
+			args = append(args, &odin.FieldValue{Name: "allocator", Value: alloc})
+		}
+		return odin.NewCall("fmt.aprintf", args...)
+	case heapConcat:
+		return translateConcat(expr.(*ast.BinaryExpr), alloc, res)
+	case heapClone:
+		res.CoreImports["strings"] = true
+		if alloc != nil {
+			return odin.NewCall("strings.clone", translateExpr(expr, res), alloc)
+		}
+		return odin.NewCall("strings.clone", translateExpr(expr, res))
+	}
+	return translateExpr(expr, res)
+}
+
+// callArgs translates call arguments, dereferencing ARC handles.
+func callArgs(call *ast.CallExpr, res *Resolver) []odin.Expr {
+	var args []odin.Expr
+	for _, arg := range call.Args {
+		if ident, ok := arg.(*ast.Ident); ok {
+			if sym, ok := res.Lookup(ident.Name); ok && sym.Strategy == AllocARC {
+				args = append(args, odin.FieldOf(odin.NewIdent(ident.Name), "data"))
+				continue
+			}
+		}
+		args = append(args, translateExpr(arg, res))
+	}
+	return args
+}
+
+// heapStrategy picks arena vs owned for a heap value, mirroring &T{}.
+func heapStrategy(kind heapKind, escapes bool) AllocStrategy {
+	// Values produced by another function were allocated on its
+	// allocator, so the frame can't reclaim them.
+	if kind == heapOwnedCall || escapes {
+		return AllocOwned
+	}
+	return AllocArena
+}
+
+// assignHeapKind classifies the value of `x := rhs` or `x = rhs`: any heap
+// allocation, or a plain string that x must copy because x also holds heap
+// strings — it was reassigned one in scope, or is an owned string already.
+func assignHeapKind(name string, rhs ast.Expr, scope ast.Node, res *Resolver) heapKind {
+	if kind := classifyHeap(rhs, res); kind != heapNone {
+		return kind
+	}
+	if !isStringExpr(rhs, res) {
+		return heapNone
+	}
+	if sym, ok := res.Lookup(name); ok && sym.Strategy == AllocOwned && sym.GoType == "string" {
+		return heapClone
+	}
+	if reassignedWithHeap(name, scope, res) {
+		return heapClone
+	}
+	return heapNone
+}
+
+// reassignedWithHeap reports whether the string variable name is given a
+// heap string by a plain assignment somewhere in scope.
+func reassignedWithHeap(name string, scope ast.Node, res *Resolver) bool {
+	if scope == nil {
+		return false
+	}
+	// Judge the assignments with name known to be a string
+	res.EnterScope()
+	defer res.ExitScope()
+	res.Define(name, &Symbol{Name: name, GoType: "string"})
+	found := false
+	ast.Inspect(scope, func(n ast.Node) bool {
+		s, ok := n.(*ast.AssignStmt)
+		if !ok || s.Tok != token.ASSIGN || len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+			return !found
+		}
+		if ident, ok := s.Lhs[0].(*ast.Ident); ok && ident.Name == name && classifyHeap(s.Rhs[0], res) != heapNone {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// translateHeapAssign emits `x := <alloc>` plus its cleanup.
+func translateHeapAssign(s *ast.AssignStmt, varName string, kind heapKind, res *Resolver) []odin.Stmt {
+	sym, ok := res.Lookup(varName)
+	prevOwned := ok && sym.Strategy == AllocOwned
+	if !ok || (sym.Strategy != AllocArena && sym.Strategy != AllocOwned) {
+		// Not seen by the function pre-pass (nested block) — own it.
+		sym = &Symbol{Name: varName, GoType: heapGoType(s.Rhs[0], kind, res), Strategy: AllocOwned}
+		res.Define(varName, sym)
+	}
+
+	var alloc odin.Expr
+	if sym.Strategy == AllocArena {
+		alloc = frameAllocator()
+	}
+	x := odin.NewIdent(varName)
+	value := heapAllocExpr(s.Rhs[0], kind, alloc, res)
+	if s.Tok == token.ASSIGN && prevOwned {
+		// The old value is freed once the new one, which may read it, is made
+		return []odin.Stmt{odin.CallStmt("golden.replace_owned", odin.AddrOf(x), value)}
+	}
+	out := []odin.Stmt{odin.NewAssign(x, s.Tok.String(), value)}
+	if sym.Strategy == AllocOwned && s.Tok == token.DEFINE && defersDelete(varName, getParentFunc(s, res.File), res) {
+		sym.DeleteDeferred = true
+		out = append(out, odin.DeferCall("delete", x))
+	}
+	return out
+}
+
+// translateHeapDecl emits `var xs []T` — a nil slice that grows via append.
+func translateHeapDecl(name, typeName string, decl ast.Node, res *Resolver) []odin.Stmt {
+	x := odin.NewIdent(name)
+	sym, ok := res.Lookup(name)
+	if ok && sym.Strategy == AllocArena {
+		return []odin.Stmt{
+			&odin.VarDecl{Name: name, Type: typeName},
+			odin.NewAssign(odin.FieldOf(x, "allocator"), "=", frameAllocator()),
+		}
+	}
+	sym = &Symbol{Name: name, GoType: typeName, Strategy: AllocOwned}
+	res.Define(name, sym)
+	out := []odin.Stmt{&odin.VarDecl{Name: name, Type: typeName}}
+	if defersDelete(name, getParentFunc(decl, res.File), res) {
+		sym.DeleteDeferred = true
+		out = append(out, odin.DeferCall("delete", x))
+	}
+	return out
+}
+
+// defersDelete reports whether the owned variable name gets a deferred
+// delete. Returns and stores hand the value on per path (see ownedVar),
+// so only a value returned on every path goes without one — or a value
+// stored where it outlives fn while fn still uses it, as it can't be
+// emptied there.
+func defersDelete(name string, fn ast.Node, res *Resolver) bool {
+	if fn == nil {
+		return true
+	}
+	if returnedOnEveryPath(name, fn) {
+		return false
+	}
+	keep := true
+	ast.Inspect(fn, func(n ast.Node) bool {
+		s, ok := n.(*ast.AssignStmt)
+		if !ok || len(s.Lhs) != len(s.Rhs) {
+			return keep
+		}
+		for i, rhs := range s.Rhs {
+			if ident, ok := rhs.(*ast.Ident); ok && ident.Name == name && outlivesFunc(s.Lhs[i], fn, res) && !isLastUse(name, s, fn) {
+				keep = false
+			}
+		}
+		return keep
+	})
+	return keep
+}
+
+// outlivesFunc reports whether a store into target hands the value to
+// something that outlives fn: a field reached through a parameter, global
+// or pointer, or one of a local struct that is returned. Locals that die
+// with fn only borrow the value.
+func outlivesFunc(target ast.Expr, fn ast.Node, res *Resolver) bool {
+	var root *ast.Ident
+	for x := target; root == nil; {
+		switch e := x.(type) {
+		case *ast.Ident:
+			if e == target {
+				return false // plain variables are rebound, not stored into
+			}
+			root = e
+		case *ast.SelectorExpr:
+			x = e.X
+		case *ast.IndexExpr:
+			x = e.X
+		case *ast.ParenExpr:
+			x = e.X
+		case *ast.StarExpr:
+			return true
+		default:
+			return true
+		}
+	}
+	sym, ok := res.Lookup(root.Name)
+	switch {
+	case !ok || sym.IsGlobal || sym.IsParam || sym.Strategy == AllocARC:
+		return true
+	case sym.Strategy == AllocArena:
+		return false // frame data dies with fn
+	case isPointerType(sym.GoType):
+		return true
+	}
+	return isReturningVar(root.Name, fn)
+}
+
+// ownedVar reports whether expr names an owned heap value with a pending
+// delete; handing it on must empty the variable with takeOwned.
+func ownedVar(expr ast.Expr, res *Resolver) (string, bool) {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	sym, ok := res.Lookup(ident.Name)
+	return ident.Name, ok && sym.Strategy == AllocOwned && sym.DeleteDeferred
+}
+
+// takeOwned moves an owned value out of its variable.
+func takeOwned(name string) odin.Expr {
+	return odin.NewCall("golden.take", odin.AddrOf(odin.NewIdent(name)))
+}
+
+// ownedCall reports whether call hands its caller an owned heap value.
+func ownedCall(call *ast.CallExpr, res *Resolver) bool {
+	return res.funcReturnsOwned[exprToStrBasic(call.Fun)] != ""
+}
+
+// hoistOwnedCalls binds the owned results of calls used inside stmt, such
+// as fmt.Println(label(x)), to temps deleted at scope end: nothing else
+// would free them. Results that are assigned, returned, sent or appended
+// already have an owner. Operands that may not run — the right side of &&
+// and ||, loop conditions — are left alone.
+func hoistOwnedCalls(stmt ast.Stmt, res *Resolver) []odin.Stmt {
+	var calls []*ast.CallExpr
+	var walk func(n ast.Node) bool
+	// owner visits an expression whose value is handed on as is
+	owner := func(x ast.Expr) {
+		if call, ok := x.(*ast.CallExpr); ok && ownedCall(call, res) {
+			for _, arg := range call.Args {
+				ast.Inspect(arg, walk)
+			}
+			return
+		}
+		ast.Inspect(x, walk)
+	}
+	walk = func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.BinaryExpr:
+			if e.Op == token.LAND || e.Op == token.LOR {
+				ast.Inspect(e.X, walk)
+				return false
+			}
+		case *ast.CallExpr:
+			ast.Inspect(e.Fun, walk)
+			appends := exprToStrBasic(e.Fun) == "append"
+			for _, arg := range e.Args {
+				if appends {
+					owner(arg)
+				} else {
+					ast.Inspect(arg, walk)
+				}
+			}
+			// After its arguments, so inner temps come first
+			if ownedCall(e, res) {
+				calls = append(calls, e)
+			}
+			return false
+		}
+		return true
+	}
+
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		owner(s.X) // a discarded owned result is deleted in place
+	case *ast.AssignStmt:
+		for _, l := range s.Lhs {
+			ast.Inspect(l, walk)
+		}
+		for _, r := range s.Rhs {
+			owner(r)
+		}
+	case *ast.ReturnStmt:
+		for _, r := range s.Results {
+			owner(r)
+		}
+	case *ast.DeclStmt:
+		if gd, ok := s.Decl.(*ast.GenDecl); ok {
+			for _, spec := range gd.Specs {
+				if vs, ok := spec.(*ast.ValueSpec); ok {
+					for _, v := range vs.Values {
+						owner(v)
+					}
+				}
+			}
+		}
+	case *ast.SendStmt:
+		ast.Inspect(s.Chan, walk)
+		owner(s.Value)
+	case *ast.DeferStmt:
+		for _, arg := range s.Call.Args {
+			ast.Inspect(arg, walk)
+		}
+	case *ast.IfStmt:
+		ast.Inspect(s.Cond, walk)
+	case *ast.RangeStmt:
+		ast.Inspect(s.X, walk)
+	}
+
+	var out []odin.Stmt
+	for _, call := range calls {
+		tmp := posName("_owned", call.Pos(), res)
+		out = append(out, odin.Define(tmp, handleCallWithResolver(call, res)), odin.DeferCall("delete", odin.NewIdent(tmp)))
+		res.ownedTemps[call] = tmp
+	}
+	return out
+}
+
+// movesOut reports whether name may leave the function at all.
+func movesOut(name string, fn ast.Node) bool {
+	if fn == nil {
+		return false
+	}
+	return isReturningVar(name, fn) || isStoredVar(name, fn)
+}
+
+// zeroSliceDecl returns the specs of `var xs []T` declarations that have
+// no initial value.
+func zeroSliceDecl(stmt ast.Stmt) []*ast.ValueSpec {
+	ds, ok := stmt.(*ast.DeclStmt)
+	if !ok {
+		return nil
+	}
+	gd, ok := ds.Decl.(*ast.GenDecl)
+	if !ok || gd.Tok != token.VAR {
+		return nil
+	}
+	var specs []*ast.ValueSpec
+	for _, spec := range gd.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok || len(vs.Values) > 0 {
+			continue
+		}
+		if at, ok := vs.Type.(*ast.ArrayType); ok && at.Len == nil {
+			specs = append(specs, vs)
+		}
+	}
+	return specs
+}
+
+// ownedResult reports the Odin type of fd's result when every return hands
+// back a freshly allocated slice, map or string — the caller then owns it.
+func ownedResult(fd *ast.FuncDecl, res *Resolver) (string, bool) {
+	if fd.Recv != nil || fd.Body == nil || fd.Type.Results == nil {
+		return "", false
+	}
+	results := fd.Type.Results.List
+	if len(results) != 1 || len(results[0].Names) > 1 {
+		return "", false
+	}
+	retType := results[0].Type
+	isString := false
+	switch t := retType.(type) {
+	case *ast.ArrayType:
+		if t.Len != nil {
+			return "", false
+		}
+	case *ast.MapType:
+	case *ast.Ident:
+		if t.Name != "string" {
+			return "", false
+		}
+		isString = true
+	default:
+		return "", false
+	}
+
+	fresh := make(map[string]bool)
+	for _, stmt := range fd.Body.List {
+		for _, vs := range zeroSliceDecl(stmt) {
+			for _, ident := range vs.Names {
+				fresh[ident.Name] = true
+			}
+		}
+		if assign, ok := stmt.(*ast.AssignStmt); ok && len(assign.Lhs) == 1 && len(assign.Rhs) == 1 {
+			if ident, ok := assign.Lhs[0].(*ast.Ident); ok && classifyHeap(assign.Rhs[0], res) != heapNone {
+				fresh[ident.Name] = true
+			}
+		}
+	}
+
+	owned, seen := true, false
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			return true
+		}
+		seen = true
+		r := ret.Results[0]
+		if ident, ok := r.(*ast.Ident); ok && (fresh[ident.Name] || (ident.Name == "nil" && !isString)) {
+			return true
+		}
+		if classifyHeap(r, res) == heapNone {
+			owned = false
+		}
+		return true
+	})
+	return mapType(retType, res), owned && seen
+}
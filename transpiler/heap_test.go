@@ -0,0 +1,41 @@
+// --- golden/transpiler/heap_test.go ---
+
+package transpiler
+
+import "testing"
+
+func TestFrameArenaAndOwnedResults(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import "fmt"
+
+func greet(name string) string {
+	msg := "hello " + name
+	return msg
+}
+
+func main() {
+	parts := []string{}
+	for i := 0; i < 3; i++ {
+		parts = append(parts, "x")
+	}
+	s := "a" + parts[0]
+	fmt.Println(s, greet("bob"), len(parts))
+}
+`)
+	wantLines(t, code,
+		// A string that is returned is allocated on the caller's allocator
+		`msg := strings.concatenate({"hello ", name})`,
+		"return msg",
+		// Locals that stay in main live in its frame arena
+		"_frame := golden.frame_begin()",
+		"defer golden.frame_end(&_frame)",
+		"parts := golden.slice_of([]string{}, golden.frame_allocator(&_frame))",
+		`append(&parts, "x")`,
+		`s := strings.concatenate({"a", parts[0]}, golden.frame_allocator(&_frame))`,
+		// and a result used inline is freed by its caller
+		`_owned_main_16_17 := greet("bob")`,
+		"defer delete(_owned_main_16_17)",
+		"fmt.println(s, _owned_main_16_17, len(parts))",
+	)
+}
@@ -0,0 +1,328 @@
+// --- golden/transpiler/internal/odin/ast.go ---
+
+// Package odin is the Odin syntax tree the transpiler emits, and the
+// printer that turns it into source. Translation only builds nodes;
+// indentation, parentheses and line breaks are the printer's business.
+//
+// Types are kept as the strings the transpiler maps Go types to
+// ("^golden.Channel(int)"); everything else is a node.
+package odin
+
+import (
+	"go/token"
+	"strconv"
+)
+
+// ── Expressions ──────────────────────────────────────────────────────────────
+
+// Expr is an Odin expression.
+type Expr interface{ exprNode() }
+
+type (
+	// Ident is a name, possibly package-qualified: x, golden.wg_add.
+	Ident struct{ Name string }
+
+	// BasicLit is a number, string or character literal, as written.
+	BasicLit struct{ Value string }
+
+	// TypeExpr is a type used as a value: make([dynamic]int), chan_make(int).
+	TypeExpr struct{ Type string }
+
+	// Unary is a prefix operation: &x, -x, !ok.
+	Unary struct {
+		Op string
+		X  Expr
+	}
+
+	// Deref is x^.
+	Deref struct{ X Expr }
+
+	// Binary is x op y, with an Odin operator.
+	Binary struct {
+		X  Expr
+		Op string
+		Y  Expr
+	}
+
+	// Selector is x.name.
+	Selector struct {
+		X    Expr
+		Name string
+	}
+
+	// Index is x[index].
+	Index struct{ X, Index Expr }
+
+	// Slice is x[lo:hi]; either bound may be nil.
+	Slice struct{ X, Lo, Hi Expr }
+
+	// Call is fun(args), or fun(a, ..rest) with Ellipsis.
+	Call struct {
+		Fun      Expr
+		Args     []Expr
+		Ellipsis bool
+	}
+
+	// Cast is cast(Type)x.
+	Cast struct {
+		Type string
+		X    Expr
+	}
+
+	// CompositeLit is Type{elts}; Type may be empty. Multiline puts one
+	// element on each line.
+	CompositeLit struct {
+		Type      string
+		Elts      []Expr
+		Multiline bool
+	}
+
+	// KeyValue is a keyed element of a map or array literal: key = value.
+	KeyValue struct{ Key, Value Expr }
+
+	// FieldValue is a named struct field or argument: name = value.
+	FieldValue struct {
+		Name  string
+		Value Expr
+	}
+
+	// ProcLit is a procedure literal.
+	ProcLit struct {
+		Params  []Field
+		Results []string
+		Body    []Stmt
+	}
+
+	// Bad stands in for what couldn't be translated: /* comment */ x.
+	// X may be nil.
+	Bad struct {
+		Comment string
+		X       Expr
+	}
+)
+
+func (*Ident) exprNode()        {}
+func (*BasicLit) exprNode()     {}
+func (*TypeExpr) exprNode()     {}
+func (*Unary) exprNode()        {}
+func (*Deref) exprNode()        {}
+func (*Binary) exprNode()       {}
+func (*Selector) exprNode()     {}
+func (*Index) exprNode()        {}
+func (*Slice) exprNode()        {}
+func (*Call) exprNode()         {}
+func (*Cast) exprNode()         {}
+func (*CompositeLit) exprNode() {}
+func (*KeyValue) exprNode()     {}
+func (*FieldValue) exprNode()   {}
+func (*ProcLit) exprNode()      {}
+func (*Bad) exprNode()          {}
+
+// Field is a struct field or a procedure parameter: name: Type.
+type Field struct {
+	Name string
+	Type string
+}
+
+// Case is a clause of a Switch; an empty List is the default case.
+type Case struct {
+	List []Expr
+	Body []Stmt
+}
+
+// ── Statements and Declarations ──────────────────────────────────────────────
+
+// Stmt is an Odin statement, or a declaration in a procedure or at file
+// level.
+type Stmt interface{ base() *stmt }
+
+// stmt holds what every statement has.
+type stmt struct {
+	// Pos is the Go position the statement was translated from; the
+	// printer reports the line it starts on. NoPos: not mapped.
+	Pos token.Pos
+}
+
+func (s *stmt) base() *stmt { return s }
+
+type (
+	// ExprStmt is an expression evaluated for its effect.
+	ExprStmt struct {
+		stmt
+		X Expr
+	}
+
+	// Assign is lhs op rhs, with op :=, = or an operator assignment.
+	Assign struct {
+		stmt
+		Lhs []Expr
+		Op  string
+		Rhs []Expr
+	}
+
+	// VarDecl is name: Type, name: Type = value, or name := value
+	// without a Type.
+	VarDecl struct {
+		stmt
+		Name  string
+		Type  string
+		Value Expr
+	}
+
+	// Return returns Results.
+	Return struct {
+		stmt
+		Results []Expr
+	}
+
+	// If is an if statement. Else is nil, an *If or a *Block.
+	If struct {
+		stmt
+		Label string
+		Cond  Expr
+		Body  []Stmt
+		Else  Stmt
+	}
+
+	// For is for {}, for cond {} or, with Init or Post, the three-clause
+	// loop.
+	For struct {
+		stmt
+		Label string
+		Init  Stmt
+		Cond  Expr
+		Post  Stmt
+		Body  []Stmt
+	}
+
+	// ForIn is for vars in x {}.
+	ForIn struct {
+		stmt
+		Label string
+		Vars  []Expr
+		X     Expr
+		Body  []Stmt
+	}
+
+	// Switch is switch Tag {cases}. Cases don't fall through, and break
+	// leaves the switch.
+	Switch struct {
+		stmt
+		Tag   Expr
+		Cases []Case
+	}
+
+	// Block is a nested scope.
+	Block struct {
+		stmt
+		Body []Stmt
+	}
+
+	// Defer defers Stmt, a call or a Block.
+	Defer struct {
+		stmt
+		Stmt Stmt
+	}
+
+	// Branch is break or continue, with an optional Label.
+	Branch struct {
+		stmt
+		Tok   string
+		Label string
+	}
+
+	// ProcDecl is Name :: proc(params) -> results {body}.
+	ProcDecl struct {
+		stmt
+		Name    string
+		Params  []Field
+		Results []string
+		Body    []Stmt
+	}
+
+	// StructDecl is Name :: struct {fields}.
+	StructDecl struct {
+		stmt
+		Name   string
+		Fields []Field
+	}
+
+	// DistinctDecl is Name :: distinct Type.
+	DistinctDecl struct {
+		stmt
+		Name string
+		Type string
+	}
+
+	// Comment is a // line comment.
+	Comment struct {
+		stmt
+		Text string
+	}
+
+	// Blank is an empty line.
+	Blank struct{ stmt }
+)
+
+// SetPos records the Go position s was translated from. An invalid pos
+// leaves it as it was.
+func SetPos(s Stmt, pos token.Pos) {
+	if pos.IsValid() {
+		s.base().Pos = pos
+	}
+}
+
+// ── Files ────────────────────────────────────────────────────────────────────
+
+// Import is import Name "Path"; Name may be empty.
+type Import struct {
+	Name string
+	Path string
+}
+
+// File is one Odin source file. Decls are set apart by blank lines.
+type File struct {
+	Package string
+	Imports []Import
+	Decls   []Stmt
+}
+
+// ── Constructors ─────────────────────────────────────────────────────────────
+
+func NewIdent(name string) *Ident { return &Ident{Name: name} }
+
+// NewCall calls the procedure named fun.
+func NewCall(fun string, args ...Expr) *Call {
+	return &Call{Fun: NewIdent(fun), Args: args}
+}
+
+// AddrOf is &x.
+func AddrOf(x Expr) *Unary { return &Unary{Op: "&", X: x} }
+
+// FieldOf is x.name.
+func FieldOf(x Expr, name string) *Selector { return &Selector{X: x, Name: name} }
+
+func IntLit(n int) *BasicLit { return &BasicLit{Value: strconv.Itoa(n)} }
+
+// StringLit quotes s.
+func StringLit(s string) *BasicLit { return &BasicLit{Value: strconv.Quote(s)} }
+
+// NewAssign is lhs op rhs for one target.
+func NewAssign(lhs Expr, op string, rhs Expr) *Assign {
+	return &Assign{Lhs: []Expr{lhs}, Op: op, Rhs: []Expr{rhs}}
+}
+
+// Define is name := value.
+func Define(name string, value Expr) *Assign {
+	return NewAssign(NewIdent(name), ":=", value)
+}
+
+// CallStmt calls the procedure named fun as a statement.
+func CallStmt(fun string, args ...Expr) *ExprStmt {
+	return &ExprStmt{X: NewCall(fun, args...)}
+}
+
+// DeferCall defers a call to the procedure named fun.
+func DeferCall(fun string, args ...Expr) *Defer {
+	return &Defer{Stmt: CallStmt(fun, args...)}
+}
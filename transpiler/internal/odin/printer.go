@@ -0,0 +1,394 @@
+// --- golden/transpiler/internal/odin/printer.go ---
+
+package odin
+
+import (
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// ── Printer ──────────────────────────────────────────────────────────────────
+//
+// One tab per block level. Expressions get parentheses exactly where
+// Odin's precedence needs them, whatever the Go source had. Multi-line
+// constructs inside expressions (proc literals, long composite literals)
+// are indented from the statement they appear in.
+
+// Mark is a statement's Go position and the line it was printed on.
+type Mark struct {
+	Line int
+	Pos  token.Pos
+}
+
+// Print renders f and marks the lines statements with a position start on.
+func Print(f *File) (string, []Mark) {
+	p := &printer{line: 1}
+	p.printf("package %s\n\n", f.Package)
+	for _, imp := range f.Imports {
+		if imp.Name != "" {
+			p.printf("import %s %s\n", imp.Name, strconv.Quote(imp.Path))
+		} else {
+			p.printf("import %s\n", strconv.Quote(imp.Path))
+		}
+	}
+	for _, d := range f.Decls {
+		p.printf("\n")
+		p.stmt(d)
+	}
+	return p.buf.String(), p.marks
+}
+
+// PrintStmts renders statements at file level, one per line.
+func PrintStmts(stmts ...Stmt) string {
+	p := &printer{line: 1}
+	p.stmts(stmts)
+	return p.buf.String()
+}
+
+// ExprString renders x on one line, as it would be printed.
+func ExprString(x Expr) string {
+	p := &printer{line: 1}
+	p.expr(x)
+	return p.buf.String()
+}
+
+type printer struct {
+	buf    strings.Builder
+	indent int
+	line   int
+	marks  []Mark
+}
+
+func (p *printer) printf(format string, args ...any) {
+	s := fmt.Sprintf(format, args...)
+	p.line += strings.Count(s, "\n")
+	p.buf.WriteString(s)
+}
+
+func (p *printer) tabs() {
+	p.buf.WriteString(strings.Repeat("\t", p.indent))
+}
+
+func (p *printer) stmts(list []Stmt) {
+	for _, s := range list {
+		p.stmt(s)
+	}
+}
+
+// stmt prints s on lines of its own.
+func (p *printer) stmt(s Stmt) {
+	if _, ok := s.(*Blank); ok {
+		p.printf("\n")
+		return
+	}
+	if pos := s.base().Pos; pos.IsValid() {
+		p.marks = append(p.marks, Mark{Line: p.line, Pos: pos})
+	}
+	p.tabs()
+	p.inline(s)
+	p.printf("\n")
+}
+
+// block prints {body}, the closing brace at the current indentation.
+func (p *printer) block(body []Stmt) {
+	p.printf("{\n")
+	p.indent++
+	p.stmts(body)
+	p.indent--
+	p.tabs()
+	p.printf("}")
+}
+
+func (p *printer) label(label string) {
+	if label != "" {
+		p.printf("%s: ", label)
+	}
+}
+
+// inline prints s from the current column, without a final newline.
+func (p *printer) inline(s Stmt) {
+	switch s := s.(type) {
+	case *ExprStmt:
+		p.expr(s.X)
+	case *Assign:
+		p.exprList(s.Lhs)
+		p.printf(" %s ", s.Op)
+		p.exprList(s.Rhs)
+	case *VarDecl:
+		switch {
+		case s.Type == "":
+			p.printf("%s := ", s.Name)
+			p.expr(s.Value)
+		case s.Value == nil:
+			p.printf("%s: %s", s.Name, s.Type)
+		default:
+			p.printf("%s: %s = ", s.Name, s.Type)
+			p.expr(s.Value)
+		}
+	case *Return:
+		p.printf("return")
+		if len(s.Results) > 0 {
+			p.printf(" ")
+			p.exprList(s.Results)
+		}
+	case *If:
+		p.label(s.Label)
+		p.printf("if ")
+		p.expr(s.Cond)
+		p.printf(" ")
+		p.block(s.Body)
+		if s.Else != nil {
+			p.printf(" else ")
+			p.inline(s.Else)
+		}
+	case *For:
+		p.label(s.Label)
+		p.printf("for ")
+		switch {
+		case s.Init != nil || s.Post != nil:
+			if s.Init != nil {
+				p.inline(s.Init)
+			}
+			p.printf("; ")
+			if s.Cond != nil {
+				p.expr(s.Cond)
+			}
+			p.printf(";")
+			if s.Post != nil {
+				p.printf(" ")
+				p.inline(s.Post)
+			}
+			p.printf(" ")
+		case s.Cond != nil:
+			p.expr(s.Cond)
+			p.printf(" ")
+		}
+		p.block(s.Body)
+	case *ForIn:
+		p.label(s.Label)
+		p.printf("for ")
+		p.exprList(s.Vars)
+		p.printf(" in ")
+		p.expr(s.X)
+		p.printf(" ")
+		p.block(s.Body)
+	case *Switch:
+		p.printf("switch ")
+		p.expr(s.Tag)
+		p.printf(" {\n")
+		for _, c := range s.Cases {
+			p.tabs()
+			if len(c.List) == 0 {
+				p.printf("case:\n")
+			} else {
+				p.printf("case ")
+				p.exprList(c.List)
+				p.printf(":\n")
+			}
+			p.indent++
+			p.stmts(c.Body)
+			p.indent--
+		}
+		p.tabs()
+		p.printf("}")
+	case *Block:
+		p.block(s.Body)
+	case *Defer:
+		p.printf("defer ")
+		p.inline(s.Stmt)
+	case *Branch:
+		p.printf("%s", s.Tok)
+		if s.Label != "" {
+			p.printf(" %s", s.Label)
+		}
+	case *ProcDecl:
+		p.printf("%s :: ", s.Name)
+		p.proc(s.Params, s.Results, s.Body)
+	case *StructDecl:
+		p.printf("%s :: struct {\n", s.Name)
+		p.indent++
+		for _, f := range s.Fields {
+			p.tabs()
+			p.printf("%s: %s,\n", f.Name, f.Type)
+		}
+		p.indent--
+		p.tabs()
+		p.printf("}")
+	case *DistinctDecl:
+		p.printf("%s :: distinct %s", s.Name, s.Type)
+	case *Comment:
+		p.printf("// %s", s.Text)
+	default:
+		panic(fmt.Sprintf("odin: unexpected statement %T", s))
+	}
+}
+
+func (p *printer) proc(params []Field, results []string, body []Stmt) {
+	var ps []string
+	for _, f := range params {
+		ps = append(ps, f.Name+": "+f.Type)
+	}
+	p.printf("proc(%s)", strings.Join(ps, ", "))
+	switch len(results) {
+	case 0:
+	case 1:
+		p.printf(" -> %s", results[0])
+	default:
+		p.printf(" -> (%s)", strings.Join(results, ", "))
+	}
+	p.printf(" ")
+	p.block(body)
+}
+
+// ── Expressions ──────────────────────────────────────────────────────────────
+
+// Binding strength, loosest first. Binary operators take theirs from
+// binaryPrec.
+const (
+	precLowest  = 0
+	precUnary   = 6
+	precPostfix = 7
+)
+
+// binaryPrec follows Odin: the same five levels as Go.
+func binaryPrec(op string) int {
+	switch op {
+	case "||":
+		return 1
+	case "&&":
+		return 2
+	case "==", "!=", "<", "<=", ">", ">=":
+		return 3
+	case "+", "-", "|", "~":
+		return 4
+	}
+	return 5 // * / % %% & &~ << >>
+}
+
+func prec(x Expr) int {
+	switch x := x.(type) {
+	case *Binary:
+		return binaryPrec(x.Op)
+	case *Unary, *Cast:
+		return precUnary
+	}
+	return precPostfix
+}
+
+func (p *printer) expr(x Expr) {
+	p.exprPrec(x, precLowest)
+}
+
+func (p *printer) exprList(list []Expr) {
+	for i, x := range list {
+		if i > 0 {
+			p.printf(", ")
+		}
+		p.expr(x)
+	}
+}
+
+// exprPrec prints x as an operand that binds at least as tightly as min,
+// in parentheses if x doesn't.
+func (p *printer) exprPrec(x Expr, min int) {
+	if prec(x) < min {
+		p.printf("(")
+		defer p.printf(")")
+	}
+	switch x := x.(type) {
+	case *Ident:
+		p.printf("%s", x.Name)
+	case *BasicLit:
+		p.printf("%s", x.Value)
+	case *TypeExpr:
+		p.printf("%s", x.Type)
+	case *Unary:
+		p.printf("%s", x.Op)
+		p.exprPrec(x.X, precUnary)
+	case *Deref:
+		p.exprPrec(x.X, precPostfix)
+		p.printf("^")
+	case *Binary:
+		bp := binaryPrec(x.Op)
+		p.exprPrec(x.X, bp)
+		p.printf(" %s ", x.Op)
+		p.exprPrec(x.Y, bp+1)
+	case *Selector:
+		p.exprPrec(x.X, precPostfix)
+		p.printf(".%s", x.Name)
+	case *Index:
+		p.exprPrec(x.X, precPostfix)
+		p.printf("[")
+		p.expr(x.Index)
+		p.printf("]")
+	case *Slice:
+		p.exprPrec(x.X, precPostfix)
+		p.printf("[")
+		if x.Lo != nil {
+			p.expr(x.Lo)
+		}
+		p.printf(":")
+		if x.Hi != nil {
+			p.expr(x.Hi)
+		}
+		p.printf("]")
+	case *Call:
+		p.exprPrec(x.Fun, precPostfix)
+		p.printf("(")
+		if n := len(x.Args); x.Ellipsis && n > 0 {
+			// Odin spreads with a prefix: f(a, ..rest)
+			p.exprList(x.Args[:n-1])
+			if n > 1 {
+				p.printf(", ")
+			}
+			p.printf("..")
+			p.exprPrec(x.Args[n-1], precUnary)
+		} else {
+			p.exprList(x.Args)
+		}
+		p.printf(")")
+	case *Cast:
+		p.printf("cast(%s)", x.Type)
+		p.exprPrec(x.X, precUnary)
+	case *CompositeLit:
+		p.compositeLit(x)
+	case *KeyValue:
+		p.expr(x.Key)
+		p.printf(" = ")
+		p.expr(x.Value)
+	case *FieldValue:
+		p.printf("%s = ", x.Name)
+		p.expr(x.Value)
+	case *ProcLit:
+		p.proc(x.Params, x.Results, x.Body)
+	case *Bad:
+		p.printf("/* %s */", x.Comment)
+		if x.X != nil {
+			p.printf(" ")
+			p.expr(x.X)
+		}
+	default:
+		panic(fmt.Sprintf("odin: unexpected expression %T", x))
+	}
+}
+
+func (p *printer) compositeLit(x *CompositeLit) {
+	p.printf("%s{", x.Type)
+	if !x.Multiline || len(x.Elts) == 0 {
+		p.exprList(x.Elts)
+		p.printf("}")
+		return
+	}
+	p.printf("\n")
+	p.indent++
+	for _, e := range x.Elts {
+		p.tabs()
+		p.expr(e)
+		p.printf(",\n")
+	}
+	p.indent--
+	p.tabs()
+	p.printf("}")
+}
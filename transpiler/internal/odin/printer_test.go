@@ -0,0 +1,39 @@
+// --- golden/transpiler/internal/odin/printer_test.go ---
+
+package odin
+
+import "testing"
+
+func TestExprString(t *testing.T) {
+	a, b, xs := NewIdent("a"), NewIdent("b"), NewIdent("xs")
+	spread := NewCall("sum", a, &Slice{X: xs, Lo: IntLit(1)})
+	spread.Ellipsis = true
+	only := NewCall("sum", xs)
+	only.Ellipsis = true
+	for _, tt := range []struct {
+		x    Expr
+		want string
+	}{
+		{spread, "sum(a, ..xs[1:])"},
+		{only, "sum(..xs)"},
+		{&Binary{Op: "*", X: &Binary{Op: "+", X: a, Y: b}, Y: IntLit(2)}, "(a + b) * 2"},
+		{&Binary{Op: "-", X: a, Y: &Binary{Op: "-", X: b, Y: IntLit(1)}}, "a - (b - 1)"},
+		{&Deref{X: FieldOf(AddrOf(a), "v")}, "(&a).v^"},
+		{&Cast{Type: "^T", X: NewIdent("data")}, "cast(^T)data"},
+	} {
+		if got := ExprString(tt.x); got != tt.want {
+			t.Errorf("ExprString = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestPrintSwitch(t *testing.T) {
+	sw := &Switch{Tag: NewCall("pick"), Cases: []Case{
+		{List: []Expr{IntLit(0)}, Body: []Stmt{CallStmt("f")}},
+		{Body: []Stmt{CallStmt("g")}},
+	}}
+	want := "switch pick() {\ncase 0:\n\tf()\ncase:\n\tg()\n}\n"
+	if got := PrintStmts(sw); got != want {
+		t.Errorf("PrintStmts =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,210 @@
+// --- golden/transpiler/internal/odin/rewrite.go ---
+
+package odin
+
+import "fmt"
+
+// Rewrite returns a copy of stmts in which f has replaced expressions.
+// f sees each expression before its operands; when it reports done, its
+// result is kept as is, otherwise the operands are rewritten in turn.
+// The input isn't modified, so nodes may be shared freely.
+//
+// Nested procedures aren't entered: an Odin proc can't see the variables
+// of the one it is declared in, so nothing in it refers to them.
+func Rewrite(stmts []Stmt, f func(Expr) (Expr, bool)) []Stmt {
+	return RewriteFree(stmts, nil, func(x Expr, _ func(string) bool) (Expr, bool) { return f(x) })
+}
+
+// RewriteFree is Rewrite for the body of a procedure, telling f which
+// names are its own: local reports whether a name, at the expression f
+// is looking at, refers to a declaration in stmts. A declaration counts
+// from the statement after it to the end of its block, as in Go; params
+// are the body's parameters, which a := at its top level reuses.
+func RewriteFree(stmts []Stmt, params []string, f func(x Expr, local func(name string) bool) (Expr, bool)) []Stmt {
+	top := make(map[string]bool)
+	for _, p := range params {
+		top[p] = false
+	}
+	r := &rewriter{f: f, scopes: []map[string]bool{top}}
+	return r.stmts(stmts)
+}
+
+// rewriter carries the scopes of the statements it is inside: each maps
+// a declared name to whether it is local (parameters aren't).
+type rewriter struct {
+	f      func(Expr, func(string) bool) (Expr, bool)
+	scopes []map[string]bool
+}
+
+func (r *rewriter) local(name string) bool {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if local, ok := r.scopes[i][name]; ok {
+			return local
+		}
+	}
+	return false
+}
+
+func (r *rewriter) push() { r.scopes = append(r.scopes, make(map[string]bool)) }
+func (r *rewriter) pop()  { r.scopes = r.scopes[:len(r.scopes)-1] }
+
+// declare brings names into the innermost scope. One already there is
+// reused, not shadowed.
+func (r *rewriter) declare(names ...Expr) {
+	scope := r.scopes[len(r.scopes)-1]
+	for _, n := range names {
+		if id, ok := n.(*Ident); ok {
+			if _, ok := scope[id.Name]; !ok {
+				scope[id.Name] = true
+			}
+		}
+	}
+}
+
+func (r *rewriter) block(list []Stmt) []Stmt {
+	r.push()
+	defer r.pop()
+	return r.stmts(list)
+}
+
+func (r *rewriter) stmts(list []Stmt) []Stmt {
+	if list == nil {
+		return nil
+	}
+	out := make([]Stmt, len(list))
+	for i, s := range list {
+		out[i] = r.stmt(s)
+	}
+	return out
+}
+
+func (r *rewriter) stmt(s Stmt) Stmt {
+	switch s := s.(type) {
+	case nil:
+		return nil
+	case *ExprStmt:
+		c := *s
+		c.X = r.expr(s.X)
+		return &c
+	case *Assign:
+		c := *s
+		c.Rhs = r.exprs(s.Rhs)
+		if s.Op == ":=" {
+			r.declare(s.Lhs...)
+		}
+		c.Lhs = r.exprs(s.Lhs)
+		return &c
+	case *VarDecl:
+		c := *s
+		c.Value = r.expr(s.Value)
+		r.declare(NewIdent(s.Name))
+		return &c
+	case *Return:
+		c := *s
+		c.Results = r.exprs(s.Results)
+		return &c
+	case *If:
+		c := *s
+		c.Cond, c.Body, c.Else = r.expr(s.Cond), r.block(s.Body), r.stmt(s.Else)
+		return &c
+	case *For:
+		c := *s
+		r.push()
+		c.Init = r.stmt(s.Init)
+		c.Cond, c.Post, c.Body = r.expr(s.Cond), r.stmt(s.Post), r.block(s.Body)
+		r.pop()
+		return &c
+	case *ForIn:
+		c := *s
+		c.X = r.expr(s.X)
+		r.push()
+		r.declare(s.Vars...)
+		c.Vars, c.Body = r.exprs(s.Vars), r.block(s.Body)
+		r.pop()
+		return &c
+	case *Switch:
+		c := *s
+		c.Tag = r.expr(s.Tag)
+		c.Cases = make([]Case, len(s.Cases))
+		for i, cc := range s.Cases {
+			c.Cases[i] = Case{List: r.exprs(cc.List), Body: r.block(cc.Body)}
+		}
+		return &c
+	case *Block:
+		c := *s
+		c.Body = r.block(s.Body)
+		return &c
+	case *Defer:
+		c := *s
+		c.Stmt = r.stmt(s.Stmt)
+		return &c
+	case *Branch, *ProcDecl, *StructDecl, *DistinctDecl, *Comment, *Blank:
+		return s
+	}
+	panic(fmt.Sprintf("odin: unexpected statement %T", s))
+}
+
+func (r *rewriter) exprs(list []Expr) []Expr {
+	if list == nil {
+		return nil
+	}
+	out := make([]Expr, len(list))
+	for i, x := range list {
+		out[i] = r.expr(x)
+	}
+	return out
+}
+
+func (r *rewriter) expr(x Expr) Expr {
+	if x == nil {
+		return nil
+	}
+	if y, done := r.f(x, r.local); done {
+		return y
+	}
+	switch x := x.(type) {
+	case *Unary:
+		c := *x
+		c.X = r.expr(x.X)
+		return &c
+	case *Deref:
+		return &Deref{X: r.expr(x.X)}
+	case *Binary:
+		c := *x
+		c.X, c.Y = r.expr(x.X), r.expr(x.Y)
+		return &c
+	case *Selector:
+		c := *x
+		c.X = r.expr(x.X)
+		return &c
+	case *Index:
+		return &Index{X: r.expr(x.X), Index: r.expr(x.Index)}
+	case *Slice:
+		return &Slice{X: r.expr(x.X), Lo: r.expr(x.Lo), Hi: r.expr(x.Hi)}
+	case *Call:
+		c := *x
+		c.Fun, c.Args = r.expr(x.Fun), r.exprs(x.Args)
+		return &c
+	case *Cast:
+		c := *x
+		c.X = r.expr(x.X)
+		return &c
+	case *CompositeLit:
+		c := *x
+		c.Elts = r.exprs(x.Elts)
+		return &c
+	case *KeyValue:
+		return &KeyValue{Key: r.expr(x.Key), Value: r.expr(x.Value)}
+	case *FieldValue:
+		c := *x
+		c.Value = r.expr(x.Value)
+		return &c
+	case *Bad:
+		c := *x
+		c.X = r.expr(x.X)
+		return &c
+	case *Ident, *BasicLit, *TypeExpr, *ProcLit:
+		return x
+	}
+	panic(fmt.Sprintf("odin: unexpected expression %T", x))
+}
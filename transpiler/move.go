@@ -0,0 +1,162 @@
+// --- golden/transpiler/move.go ---
+
+package transpiler
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/v4rm4n/golden/transpiler/internal/odin"
+)
+
+// ── Ownership Moves ──────────────────────────────────────────────────────────
+//
+// An ARC owner normally gets `defer golden.arc_release(&x)`. When x is
+// passed on at its final use — returned, captured by a goroutine, or bound
+// to a new name — ownership is transferred with golden.arc_move(&x) instead
+// of a retain/release pair: the handle is emptied, so the deferred release
+// becomes a no-op on that path while every other path still releases.
+//
+// If x is returned on every path, even the defer is dropped.
+
+// isLastUse reports whether the use of name at node is its final one in fn:
+// nothing mentions it afterwards and no enclosing loop can run node again.
+func isLastUse(name string, node ast.Node, fn ast.Node) bool {
+	if fn == nil {
+		return false
+	}
+	last := true
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if !last || n == nil {
+			return false
+		}
+		switch x := n.(type) {
+		case *ast.Ident:
+			if x.Name == name && x.Pos() >= node.End() {
+				last = false
+			}
+		case *ast.ForStmt:
+			if encloses(x, node) && !declaresIn(name, x.Body) {
+				last = false
+			}
+		case *ast.RangeStmt:
+			if encloses(x, node) && !declaresIn(name, x.Body) {
+				last = false
+			}
+		}
+		return true
+	})
+	return last
+}
+
+func encloses(outer, inner ast.Node) bool {
+	return outer.Pos() <= inner.Pos() && inner.End() <= outer.End()
+}
+
+// declaresIn reports whether name is introduced with := inside block.
+func declaresIn(name string, block *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(block, func(n ast.Node) bool {
+		if assign, ok := n.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE {
+			for _, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name {
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// returnedOnEveryPath reports whether fn ends in a return of name and every
+// other return hands name back too — ownership always leaves with it.
+func returnedOnEveryPath(name string, fn ast.Node) bool {
+	fd, ok := fn.(*ast.FuncDecl)
+	if !ok || fd.Body == nil || len(fd.Body.List) == 0 {
+		return false
+	}
+	if _, ok := fd.Body.List[len(fd.Body.List)-1].(*ast.ReturnStmt); !ok {
+		return false
+	}
+	every := true
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok {
+			return every
+		}
+		returned := false
+		for _, r := range ret.Results {
+			if ident, ok := r.(*ast.Ident); ok && ident.Name == name {
+				returned = true
+			}
+		}
+		every = every && returned
+		return every
+	})
+	return every
+}
+
+// arcReleaseDefer returns the cleanup for a new ARC owner declared at s.
+func arcReleaseDefer(name string, s ast.Node, res *Resolver) []odin.Stmt {
+	if returnedOnEveryPath(name, getParentFunc(s, res.File)) {
+		return nil
+	}
+	return []odin.Stmt{odin.DeferCall("golden.arc_release", odin.AddrOf(odin.NewIdent(name)))}
+}
+
+// arcHandOff passes an ARC owner on at node: a move at its final use,
+// otherwise a retain so both sides hold a reference.
+func arcHandOff(name string, node ast.Node, res *Resolver) odin.Expr {
+	if isLastUse(name, node, getParentFunc(node, res.File)) {
+		return odin.NewCall("golden.arc_move", odin.AddrOf(odin.NewIdent(name)))
+	}
+	return odin.NewCall("golden.retain", odin.NewIdent(name))
+}
+
+// isArcOwner reports whether expr is a plain reference to an ARC variable.
+func isArcOwner(expr ast.Expr, res *Resolver) (string, bool) {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	sym, ok := res.Lookup(ident.Name)
+	return ident.Name, ok && sym.Strategy == AllocARC
+}
+
+// isJoinedGo reports whether a goroutine's lifetime is provably nested in
+// its spawning function: its body defers wg.Done() and the function waits
+// on that same WaitGroup later at top level. Captures of such goroutines
+// don't escape, so they need no refcounting.
+func isJoinedGo(g *ast.GoStmt, body *ast.BlockStmt) bool {
+	fn, ok := g.Call.Fun.(*ast.FuncLit)
+	if !ok {
+		return false
+	}
+	wg := ""
+	for _, stmt := range fn.Body.List {
+		if d, ok := stmt.(*ast.DeferStmt); ok {
+			if sel, ok := d.Call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Done" {
+				wg = exprToStrBasic(sel.X)
+			}
+		}
+	}
+	if wg == "" {
+		return false
+	}
+	for _, stmt := range body.List {
+		es, ok := stmt.(*ast.ExprStmt)
+		if !ok || stmt.Pos() < g.End() {
+			continue
+		}
+		if call, ok := es.X.(*ast.CallExpr); ok {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Wait" && exprToStrBasic(sel.X) == wg {
+				return true
+			}
+		}
+	}
+	return false
+}
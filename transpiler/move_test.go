@@ -0,0 +1,51 @@
+// --- golden/transpiler/move_test.go ---
+
+package transpiler
+
+import "testing"
+
+func TestArcMovesAtLastUse(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import "fmt"
+
+type Node struct{ V int }
+
+func build(v int) *Node {
+	n := &Node{V: v}
+	return n
+}
+
+func pick(flag bool) *Node {
+	n := &Node{V: 1}
+	if flag {
+		return n
+	}
+	fmt.Println(n.V)
+	return nil
+}
+
+func main() {
+	a := build(1)
+	b := a
+	c := pick(true)
+	fmt.Println(b.V, c.V)
+}
+`)
+	wantLines(t, code,
+		// Returned on every path: no release at all
+		"build :: proc(v: int) -> golden.Arc(Node) {",
+		"n := golden.make_arc(Node{V = v})",
+		"return n",
+		// Returned on one path: moved there, released on the others
+		"pick :: proc(flag: b8) -> golden.Arc(Node) {",
+		"defer golden.arc_release(&n)",
+		"return golden.arc_move(&n)",
+		// Rebound at its last use: moved instead of retained
+		"a := build(1)",
+		"defer golden.arc_release(&a)",
+		"b := golden.arc_move(&a)",
+		"fmt.println(b.data.V, c.data.V)",
+	)
+	rejectLines(t, code, "golden.retain(a)")
+}
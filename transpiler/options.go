@@ -0,0 +1,61 @@
+// --- golden/transpiler/options.go ---
+
+package transpiler
+
+import "fmt"
+
+// MemoryMode selects how much memory checking the generated program does.
+type MemoryMode int
+
+const (
+	// MemDebug installs the tracking allocator, reports leaks and bad frees,
+	// poisons freed ARC data and asserts on refcount underflow.
+	MemDebug MemoryMode = iota
+	// MemRelease skips all tracking — no overhead in shipped builds.
+	MemRelease
+	// MemStrict is MemDebug plus a non-zero exit on any leak or bad free,
+	// so CI catches regressions.
+	MemStrict
+)
+
+func (m MemoryMode) String() string {
+	switch m {
+	case MemRelease:
+		return "release"
+	case MemStrict:
+		return "strict"
+	}
+	return "debug"
+}
+
+// ParseMemoryMode parses "debug", "release" or "strict".
+func ParseMemoryMode(s string) (MemoryMode, error) {
+	switch s {
+	case "debug", "":
+		return MemDebug, nil
+	case "release":
+		return MemRelease, nil
+	case "strict":
+		return MemStrict, nil
+	}
+	return MemDebug, fmt.Errorf("unknown memory mode %q (want debug, release or strict)", s)
+}
+
+// Options tunes the generated program.
+type Options struct {
+	Memory MemoryMode
+	// Race instruments accesses to variables goroutines share and turns
+	// on the runtime's happens-before race detector.
+	Race bool
+	// Deterministic runs goroutines one at a time on the runtime's
+	// cooperative scheduler, switching only at channel, WaitGroup and
+	// Mutex operations. Seed picks the interleaving; the same seed
+	// replays the same schedule.
+	Deterministic bool
+	Seed          uint64
+	// Workers sizes the task pool; 0 means one worker per CPU core.
+	Workers int
+	// Packages maps Go import paths to Odin packages that stand in for
+	// them: pkg.DoThing(x) becomes pkg.do_thing(x) against the Odin import.
+	Packages map[string]string
+}
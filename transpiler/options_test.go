@@ -0,0 +1,56 @@
+// --- golden/transpiler/options_test.go ---
+
+package transpiler
+
+import "testing"
+
+const helloSource = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("hi")
+}
+`
+
+func TestMemoryModes(t *testing.T) {
+	for _, s := range []string{"debug", "release", "strict", ""} {
+		m, err := ParseMemoryMode(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := s; want != "" && m.String() != want {
+			t.Errorf("ParseMemoryMode(%q) = %s", s, m)
+		}
+	}
+	if _, err := ParseMemoryMode("fast"); err == nil {
+		t.Error("ParseMemoryMode accepted fast")
+	}
+
+	debug, _ := transpileWith(t, Options{Memory: MemDebug}, helloSource)
+	wantLines(t, debug, "track: mem.Tracking_Allocator", "golden.debug_memory = true")
+	rejectLines(t, debug, "os.exit(1)")
+
+	strict, _ := transpileWith(t, Options{Memory: MemStrict}, helloSource)
+	wantLines(t, strict,
+		"track: mem.Tracking_Allocator",
+		"if len(track.allocation_map) > 0 || len(track.bad_free_array) > 0 {",
+		"os.exit(1)",
+		"golden.debug_memory = true",
+	)
+
+	release, _ := transpileWith(t, Options{Memory: MemRelease}, helloSource)
+	rejectLines(t, release, "track: mem.Tracking_Allocator", "golden.debug_memory = true")
+	wantLines(t, release, "golden.pool_start()", `fmt.println("hi")`)
+}
+
+func TestDeterministicScheduling(t *testing.T) {
+	code, _ := transpileWith(t, Options{Deterministic: true, Seed: 7, Workers: 3}, helloSource)
+	wantLines(t, code,
+		"golden.sched_deterministic(7)",
+		"golden.pool_start(3)",
+		"defer golden.pool_stop()",
+	)
+	plain, _ := transpileWith(t, Options{}, helloSource)
+	rejectLines(t, plain, "golden.sched_deterministic(7)", "golden.sched_deterministic(0)")
+}
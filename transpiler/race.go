@@ -0,0 +1,202 @@
+// --- golden/transpiler/race.go ---
+
+package transpiler
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/v4rm4n/golden/transpiler/internal/odin"
+)
+
+// ── -race Instrumentation ────────────────────────────────────────────────────
+//
+// With Options.Race, statements that touch memory goroutines share are
+// preceded by golden.race_read / golden.race_write on the addresses
+// involved, and the runtime checks them against its vector clocks.
+// Shared means named by a go statement in the same function and reachable
+// from both sides of it:
+//
+//	ARC data             s.hits++  →  golden.race_write(&s.data.hits)
+//	pointers             p.n       →  golden.race_read(&p.n)
+//	address taken (&x)   total     →  golden.race_read(&total)
+//	structs with locks   c.n       →  golden.race_read(&c.n)
+//
+// Other values are copied into the goroutine, so they can't race.
+// Accesses made through pointers handed to other functions aren't seen.
+
+// raceCandidates collects the names go statements in body refer to. The
+// value records whether one of them takes the name's address.
+func raceCandidates(body *ast.BlockStmt) map[string]bool {
+	names := make(map[string]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		g, ok := n.(*ast.GoStmt)
+		if !ok {
+			return true
+		}
+		ast.Inspect(g.Call, func(n ast.Node) bool {
+			switch e := n.(type) {
+			case *ast.UnaryExpr:
+				if ident, ok := e.X.(*ast.Ident); ok && e.Op == token.AND {
+					names[ident.Name] = true
+				}
+			case *ast.Ident:
+				if _, seen := names[e.Name]; !seen {
+					names[e.Name] = false
+				}
+			}
+			return true
+		})
+		return true
+	})
+	return names
+}
+
+// raceAddr returns the Odin address of a shared location, if expr is one.
+func raceAddr(expr ast.Expr, res *Resolver) (odin.Expr, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		addressed, ok := res.RaceShared[e.Name]
+		if !ok || !addressed {
+			return nil, false
+		}
+		sym, ok := res.Lookup(e.Name)
+		if !ok || sym.IsGlobal || sym.Strategy == AllocARC || isPointerType(sym.GoType) || isSharedSyncType(sym.GoType) {
+			return nil, false
+		}
+		return odin.AddrOf(odin.NewIdent(e.Name)), true
+
+	case *ast.SelectorExpr:
+		ident, ok := e.X.(*ast.Ident)
+		if !ok {
+			return nil, false
+		}
+		addressed, ok := res.RaceShared[ident.Name]
+		if !ok {
+			return nil, false
+		}
+		sym, ok := res.Lookup(ident.Name)
+		if !ok || sym.IsGlobal {
+			return nil, false
+		}
+		fieldType, isField := res.structFields[exprStructType(e.X, res)][e.Sel.Name]
+		if !isField || isSharedSyncType(fieldType) {
+			return nil, false // methods, and locks that synchronise themselves
+		}
+		switch {
+		case sym.Strategy == AllocARC:
+			return odin.AddrOf(odin.FieldOf(odin.FieldOf(odin.NewIdent(ident.Name), "data"), e.Sel.Name)), true
+		case isPointerType(sym.GoType) && !strings.HasPrefix(sym.GoType, "^golden."),
+			addressed, hasSyncFields(sym.GoType, res):
+			return odin.AddrOf(odin.FieldOf(odin.NewIdent(ident.Name), e.Sel.Name)), true
+		}
+	}
+	return nil, false
+}
+
+// raceAccesses gathers one statement's shared accesses in source order.
+type raceAccesses struct {
+	res   *Resolver
+	order []odin.Expr
+	write map[string]bool // by printed address
+}
+
+func (a *raceAccesses) add(addr odin.Expr, write bool) {
+	key := odin.ExprString(addr)
+	if _, seen := a.write[key]; !seen {
+		a.order = append(a.order, addr)
+	}
+	a.write[key] = a.write[key] || write
+}
+
+// target records an assignment target: a write if shared, otherwise the
+// reads needed to reach it (xs[i] reads xs).
+func (a *raceAccesses) target(expr ast.Expr) {
+	if addr, ok := raceAddr(expr, a.res); ok {
+		a.add(addr, true)
+		return
+	}
+	a.reads(expr)
+}
+
+func (a *raceAccesses) reads(expr ast.Expr) {
+	if expr == nil {
+		return
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.UnaryExpr:
+			// &x takes an address; it reads nothing
+			return e.Op != token.AND
+		case *ast.CallExpr:
+			if _, ok := e.Fun.(*ast.SelectorExpr); ok {
+				// A method receiver is passed along, not read here
+				for _, arg := range e.Args {
+					a.reads(arg)
+				}
+				return false
+			}
+		case *ast.Ident:
+			if addr, ok := raceAddr(e, a.res); ok {
+				a.add(addr, false)
+			}
+		case *ast.SelectorExpr:
+			if addr, ok := raceAddr(e, a.res); ok {
+				a.add(addr, false)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// raceHooks returns the race_read/race_write calls that go before stmt.
+// Compound statements only check their header; their bodies are
+// statements of their own.
+func raceHooks(stmt ast.Stmt, res *Resolver) []odin.Stmt {
+	if !res.Opts.Race || len(res.RaceShared) == 0 {
+		return nil
+	}
+	a := &raceAccesses{res: res, write: make(map[string]bool)}
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		if s.Tok != token.DEFINE {
+			for _, l := range s.Lhs {
+				a.target(l)
+			}
+		}
+		for _, r := range s.Rhs {
+			a.reads(r)
+		}
+	case *ast.IncDecStmt:
+		a.target(s.X)
+	case *ast.ExprStmt:
+		a.reads(s.X)
+	case *ast.SendStmt:
+		a.reads(s.Chan)
+		a.reads(s.Value)
+	case *ast.ReturnStmt:
+		for _, r := range s.Results {
+			a.reads(r)
+		}
+	case *ast.IfStmt:
+		a.reads(s.Cond)
+	case *ast.ForStmt:
+		a.reads(s.Cond)
+	case *ast.RangeStmt:
+		a.reads(s.X)
+	}
+
+	var hooks []odin.Stmt
+	for _, addr := range a.order {
+		hook := "golden.race_read"
+		if a.write[odin.ExprString(addr)] {
+			hook = "golden.race_write"
+		}
+		hooks = append(hooks, odin.CallStmt(hook, addr))
+	}
+	return hooks
+}
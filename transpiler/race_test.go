@@ -0,0 +1,48 @@
+// --- golden/transpiler/race_test.go ---
+
+package transpiler
+
+import "testing"
+
+func TestRaceInstrumentsSharedAccesses(t *testing.T) {
+	src := `package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+type Stats struct{ count int }
+
+func main() {
+	var wg sync.WaitGroup
+	stats := &Stats{}
+	total := 0
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p := &total
+		_ = p
+		total += 1
+		fmt.Println(stats.count)
+	}()
+	wg.Wait()
+	fmt.Println(total)
+}
+`
+	code, diags := transpileWith(t, Options{Race: true}, src)
+	if HasErrors(diags) {
+		t.Fatalf("diagnostics: %v", diags)
+	}
+	wantLines(t, code,
+		"golden.race_enabled = true",
+		"_ctx_main_15_2.total = &total",
+		"golden.race_write(_gctx.total)",
+		"_gctx.total^ += 1",
+		"golden.race_read(&_gctx.stats.count)",
+		"golden.race_read(&total)",
+	)
+
+	plain := transpileSource(t, src)
+	rejectLines(t, plain, "golden.race_enabled = true", "golden.race_write(_gctx.total)")
+}
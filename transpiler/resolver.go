@@ -1,4 +1,4 @@
-// --- golden/internal/transpiler/resolver.go ---
+// --- golden/transpiler/resolver.go ---
 
 package transpiler
 
@@ -14,6 +14,7 @@ const (
 	AllocNone AllocStrategy = iota
 	AllocARC
 	AllocArena
+	AllocOwned // heap value freed by its owner with delete()
 )
 
 type Symbol struct {
@@ -22,7 +23,11 @@ type Symbol struct {
 	IsPtr    bool
 	Escapes  bool // Result of Escape Analysis
 	IsGlobal bool
+	IsParam  bool // Odin parameters are immutable; assigning one shadows it
 	Strategy AllocStrategy
+	// DeleteDeferred: an owned heap value with a pending `defer delete`;
+	// returns and stores move it out with golden.take
+	DeleteDeferred bool
 }
 
 type Scope struct {
@@ -32,18 +37,50 @@ type Scope struct {
 
 type Resolver struct {
 	File        *ast.File
+	Fset        *token.FileSet // nil when no source map is wanted
+	Opts        Options
+	ReturnsArc  bool // current function hands back golden.Arc results
 	PackageName string
 	Imports     map[string]string // Key: Alias/Name (os), Value: Path ("os")
 	GlobalScope *Scope
 	Current     *Scope
+	CoreImports map[string]bool          // Extra core:* packages the output needs
+	RaceShared  map[string]bool          // -race: names goroutines of the current function touch (true: by address)
+	ownedTemps  map[*ast.CallExpr]string // owned call results bound to temps (see hoistOwnedCalls)
+
+	census
+	diags *diagSink
+}
+
+// census is what the first pass learns about the package's declarations.
+// It belongs to one translation, so translations can run side by side.
+type census struct {
+	funcReturnTypes  map[string]string            // func → T of its *T result
+	methodIsPointer  map[string]bool              // method → has a pointer receiver
+	funcReturnsOwned map[string]string            // func → Odin type of the heap value it hands back
+	structFields     map[string]map[string]string // struct → field → Odin type
+	funcParamTypes   map[string][]string          // func → Odin parameter types
+	syncMapTypes     map[string]string            // sync.Map var → golden.Sync_Map(K, V)
 }
 
 func NewResolver() *Resolver {
 	global := &Scope{Symbols: make(map[string]*Symbol)}
 	return &Resolver{
 		Imports:     make(map[string]string),
+		CoreImports: make(map[string]bool),
+		ownedTemps:  make(map[*ast.CallExpr]string),
 		GlobalScope: global,
 		Current:     global,
+		census: census{
+			funcReturnTypes:  make(map[string]string),
+			methodIsPointer:  make(map[string]bool),
+			funcReturnsOwned: make(map[string]string),
+			// sync.Cond's Locker, for c.L.Lock()
+			structFields:   map[string]map[string]string{"golden.Cond": {"L": "^sync.Mutex"}},
+			funcParamTypes: make(map[string][]string),
+			syncMapTypes:   make(map[string]string),
+		},
+		diags: newDiagSink(nil),
 	}
 }
 
@@ -0,0 +1,156 @@
+// --- golden/transpiler/select.go ---
+
+package transpiler
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/v4rm4n/golden/transpiler/internal/odin"
+)
+
+// ── select ───────────────────────────────────────────────────────────────────
+//
+// A select is one golden.select_wait over its cases, switching on the
+// index of the case that completed. Channel operands and sent values are
+// evaluated once, before waiting, as in Go; a received value lands in a
+// temporary that the chosen clause then assigns from.
+//
+//	select {                   {
+//	case v := <-ch:                _recv_main_9_2: int
+//		use(v)                     switch golden.select_wait({golden.select_recv(ch, &_recv_main_9_2)}, true) {
+//	default:                       case 0:
+//	}                                  v := _recv_main_9_2
+//	                                   use(v)
+//	                               case:
+//	                               }
+//	                           }
+//
+// Odin's break leaves a switch just as Go's leaves a select. A time.After
+// operand is dropped when the select is done, so the timer of a case
+// that lost doesn't outlive it.
+
+func translateSelect(s *ast.SelectStmt, res *Resolver) []odin.Stmt {
+	var temps []odin.Stmt
+	var cases []odin.Expr
+	var clauses []odin.Case
+	hasDefault := false
+
+	for _, stmt := range s.Body.List {
+		cc := stmt.(*ast.CommClause)
+		res.EnterScope()
+		pre, ok := selectCase(cc.Comm, &temps, &cases, res)
+		if !ok {
+			res.ExitScope()
+			res.report(s.Pos(), Error, CatStatement, "select statement", "unsupported select case: %s", nodeKind(cc.Comm))
+			return []odin.Stmt{&odin.Comment{Text: "unsupported select statement"}}
+		}
+		clause := odin.Case{Body: append(pre, translateBody(cc.Body, res)...)}
+		if cc.Comm != nil {
+			clause.List = []odin.Expr{odin.IntLit(len(cases) - 1)}
+		} else {
+			hasDefault = true
+		}
+		clauses = append(clauses, clause)
+		res.ExitScope()
+	}
+
+	wait := odin.NewCall("golden.select_wait", &odin.CompositeLit{Type: "[]golden.Select_Case", Elts: cases})
+	if hasDefault {
+		wait.Args = append(wait.Args, odin.NewIdent("true"))
+	}
+	sw := &odin.Switch{Tag: wait, Cases: clauses}
+	if len(temps) == 0 {
+		return []odin.Stmt{sw}
+	}
+	return []odin.Stmt{&odin.Block{Body: append(temps, sw)}}
+}
+
+// selectCase adds the golden.select_* case for a clause's communication
+// and returns the statements that bind its received values.
+func selectCase(comm ast.Stmt, temps *[]odin.Stmt, cases *[]odin.Expr, res *Resolver) ([]odin.Stmt, bool) {
+	switch c := comm.(type) {
+	case nil:
+		return nil, true
+	case *ast.SendStmt:
+		send := posName("_send", c.Pos(), res)
+		*temps = append(*temps, &odin.VarDecl{Name: send, Type: chanElemType(c.Chan, res), Value: translateExpr(c.Value, res)})
+		*cases = append(*cases, odin.NewCall("golden.select_send", selectChan(c.Chan, temps, res), odin.AddrOf(odin.NewIdent(send))))
+		return nil, true
+	case *ast.ExprStmt:
+		if recv, ok := c.X.(*ast.UnaryExpr); ok && recv.Op == token.ARROW {
+			*cases = append(*cases, odin.NewCall("golden.select_recv", selectChan(recv.X, temps, res)))
+			return nil, true
+		}
+	case *ast.AssignStmt:
+		recv, ok := c.Rhs[0].(*ast.UnaryExpr)
+		if !ok || recv.Op != token.ARROW {
+			return nil, false
+		}
+		elem := chanElemType(recv.X, res)
+		if elem == "" {
+			return nil, false
+		}
+		// v, ok := <-ch: the value, then whether it was sent
+		var pre []odin.Stmt
+		args := []odin.Expr{selectChan(recv.X, temps, res)}
+		for i, lhs := range c.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok && ident.Name == "_" {
+				args = append(args, odin.NewIdent("nil"))
+				continue
+			}
+			tmp, t := posName("_recv", c.Pos(), res), elem
+			if i == 1 {
+				tmp, t = posName("_ok", c.Pos(), res), "bool"
+			}
+			*temps = append(*temps, &odin.VarDecl{Name: tmp, Type: t})
+			args = append(args, odin.AddrOf(odin.NewIdent(tmp)))
+			if ident, ok := lhs.(*ast.Ident); ok && c.Tok == token.DEFINE {
+				res.Define(ident.Name, &Symbol{Name: ident.Name, GoType: t})
+			}
+			pre = append(pre, odin.NewAssign(translateExpr(lhs, res), c.Tok.String(), odin.NewIdent(tmp)))
+		}
+		*cases = append(*cases, odin.NewCall("golden.select_recv", args...))
+		return pre, true
+	}
+	return nil, false
+}
+
+// selectChan evaluates a case's channel operand. time.After gets a
+// temporary that is dropped with the select.
+func selectChan(x ast.Expr, temps *[]odin.Stmt, res *Resolver) odin.Expr {
+	call, ok := x.(*ast.CallExpr)
+	if !ok || exprToStrBasic(call.Fun) != "time.After" {
+		return translateExpr(x, res)
+	}
+	after := odin.NewIdent(posName("_after", call.Pos(), res))
+	*temps = append(*temps,
+		odin.Define(after.Name, translateExpr(call, res)),
+		odin.DeferCall("golden.time_after_done", after))
+	return after
+}
+
+// chanElemType is the element type of a channel expression, "" if unknown.
+func chanElemType(x ast.Expr, res *Resolver) string {
+	t := ""
+	switch e := x.(type) {
+	case *ast.Ident:
+		if sym, ok := res.Lookup(e.Name); ok {
+			t = sym.GoType
+		}
+	case *ast.CallExpr:
+		t = stdCallType(e, res) // time.After, ctx.Done()
+	case *ast.SelectorExpr:
+		if e.Sel.Name == "C" && recvType(e.X, res) == "golden.Timer" {
+			return "time.Time"
+		}
+		t = res.structFields[exprStructType(e.X, res)][e.Sel.Name]
+	case *ast.ParenExpr:
+		return chanElemType(e.X, res)
+	}
+	if !strings.HasPrefix(t, "^golden.Channel(") {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(t, "^golden.Channel("), ")")
+}
@@ -0,0 +1,75 @@
+// --- golden/transpiler/select_test.go ---
+
+package transpiler
+
+import "testing"
+
+func TestSelect(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func main() {
+	ch := make(chan int)
+	out := make(chan string)
+	select {
+	case v, ok := <-ch:
+		fmt.Println(v, ok)
+	case out <- "hi":
+		fmt.Println("sent")
+	case <-time.After(time.Second):
+		fmt.Println("timeout")
+	default:
+	}
+}
+`)
+	wantLines(t, code,
+		"_recv_main_12_7: int",
+		"_ok_main_12_7: bool",
+		`_send_main_14_7: string = "hi"`,
+		"_after_main_16_9 := golden.time_after(time.Second)",
+		"defer golden.time_after_done(_after_main_16_9)",
+		"switch golden.select_wait([]golden.Select_Case{golden.select_recv(ch, &_recv_main_12_7, &_ok_main_12_7), golden.select_send(out, &_send_main_14_7), golden.select_recv(_after_main_16_9)}, true) {",
+		"case 0:",
+		"v := _recv_main_12_7",
+		"ok := _ok_main_12_7",
+		"fmt.println(v, ok)",
+		"case 1:",
+		"case 2:",
+		"case:",
+	)
+}
+
+func TestSelectTimerAndContext(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import (
+	"context"
+	"time"
+)
+
+func wait(ctx context.Context) error {
+	t := time.NewTimer(time.Second)
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func main() {}
+`)
+	wantLines(t, code,
+		"t := golden.timer_new(time.Second)",
+		"defer golden.timer_detach(t)",
+		"switch golden.select_wait([]golden.Select_Case{golden.select_recv(t.C), golden.select_recv(golden.context_done(ctx))}) {",
+		"case 0:",
+		"return nil",
+		"case 1:",
+		"return golden.context_err(ctx)",
+	)
+}
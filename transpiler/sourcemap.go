@@ -0,0 +1,109 @@
+// --- golden/transpiler/sourcemap.go ---
+
+package transpiler
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/v4rm4n/golden/transpiler/internal/odin"
+)
+
+// ── Source Map ───────────────────────────────────────────────────────────────
+//
+// While translating, every statement's first emitted Odin statement is
+// tagged with its token.Pos. The printer reports the line each tagged
+// statement starts on, which gives "generated line → Go position".
+//
+// The table is embedded in main.odin so the runtime can translate tracking
+// allocator reports and panics back to file.go:line:col.
+
+// SourceLine maps one generated Odin line to the Go position it came from.
+type SourceLine struct {
+	OdinLine int
+	Pos      token.Position
+}
+
+// SourceMap is sorted by OdinLine. Lines without an entry belong to the
+// closest entry above them.
+type SourceMap []SourceLine
+
+// Lookup returns the Go position for a generated line.
+func (m SourceMap) Lookup(odinLine int) (token.Position, bool) {
+	i := sort.Search(len(m), func(i int) bool { return m[i].OdinLine > odinLine })
+	if i == 0 {
+		return token.Position{}, false
+	}
+	return m[i-1].Pos, true
+}
+
+// markPos tags a generated statement with the Go position it came from.
+func markPos(pos token.Pos, s odin.Stmt) odin.Stmt {
+	odin.SetPos(s, pos)
+	return s
+}
+
+// goPosLiteral renders pos as a quoted "file.go:line:col" Odin string for
+// runtime diagnostics, falling back to fallback without a FileSet.
+func goPosLiteral(pos token.Pos, fallback string, res *Resolver) odin.Expr {
+	if res.Fset == nil || !pos.IsValid() {
+		return odin.StringLit(fallback)
+	}
+	p := res.Fset.Position(pos)
+	return odin.StringLit(fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column))
+}
+
+// posName names a generated declaration after the Go position it stands
+// for: prefix_file_line_col. Unlike a token.Pos offset, that doesn't
+// shift when other files of the package change.
+func posName(prefix string, pos token.Pos, res *Resolver) string {
+	if res.Fset == nil || !pos.IsValid() {
+		return fmt.Sprintf("%s_%d", prefix, pos)
+	}
+	p := res.Fset.Position(pos)
+	file := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.TrimSuffix(filepath.Base(p.Filename), ".go"))
+	return fmt.Sprintf("%s_%s_%d_%d", prefix, file, p.Line, p.Column)
+}
+
+// markFirst tags the first statement of a translation.
+func markFirst(pos token.Pos, stmts []odin.Stmt) []odin.Stmt {
+	if len(stmts) > 0 {
+		markPos(pos, stmts[0])
+	}
+	return stmts
+}
+
+// sourceMap resolves the printer's marks against fset.
+func sourceMap(marks []odin.Mark, fset *token.FileSet) SourceMap {
+	if fset == nil {
+		return nil
+	}
+	var sm SourceMap
+	for _, m := range marks {
+		sm = append(sm, SourceLine{OdinLine: m.Line, Pos: fset.Position(m.Pos)})
+	}
+	return sm
+}
+
+// odinTable declares the map as the _golden_source_map global that
+// golden.source_map_install() expects.
+func (m SourceMap) odinTable() []odin.Stmt {
+	table := &odin.CompositeLit{Type: "[?]golden.Source_Line", Multiline: true}
+	for _, l := range m {
+		table.Elts = append(table.Elts, &odin.CompositeLit{Elts: []odin.Expr{
+			odin.IntLit(l.OdinLine), odin.StringLit(l.Pos.Filename), odin.IntLit(l.Pos.Line), odin.IntLit(l.Pos.Column),
+		}})
+	}
+	return []odin.Stmt{
+		&odin.Comment{Text: "Generated line → Go position, for golden.source_pos()"},
+		&odin.VarDecl{Name: "_golden_source_map", Value: table},
+	}
+}
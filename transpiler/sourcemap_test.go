@@ -0,0 +1,58 @@
+// --- golden/transpiler/sourcemap_test.go ---
+
+package transpiler
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestSourceMapPointsAtGoLines(t *testing.T) {
+	// Both files import fmt; the duplicate must not shift the mapping
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, src := range []struct{ name, code string }{
+		{"a.go", "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tx := 1\n\tfmt.Println(x)\n\thelp()\n}\n"},
+		{"b.go", "package main\n\nimport \"fmt\"\n\nfunc help() {\n\tfmt.Println(\"help\")\n}\n"},
+	} {
+		f, err := parser.ParseFile(fset, src.name, src.code, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	res, err := New(Config{}).Transpile(context.Background(), []*Package{{Path: "main", Fset: fset, Files: files}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := res.Files[0]
+	lines := strings.Split(file.Code, "\n")
+	for want, pos := range map[string]string{
+		"x := 1":              "a.go:6:2",
+		"fmt.println(x)":      "a.go:7:2",
+		"help()":              "a.go:8:2",
+		"help :: proc() {":    "b.go:5:1",
+		`fmt.println("help")`: "b.go:6:2",
+	} {
+		found := false
+		for i, l := range lines {
+			if strings.TrimSpace(l) != want {
+				continue
+			}
+			found = true
+			if got, ok := file.SourceMap.Lookup(i + 1); !ok || got.String() != pos {
+				t.Errorf("line %d (%s) maps to %v, want %s", i+1, want, got, pos)
+			}
+		}
+		if !found {
+			t.Errorf("no line %s in\n%s", want, file.Code)
+		}
+	}
+	if strings.Count(file.Code, `import "core:fmt"`) != 1 {
+		t.Errorf("core:fmt imported other than once:\n%s", file.Code)
+	}
+}
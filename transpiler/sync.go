@@ -0,0 +1,454 @@
+// --- golden/transpiler/sync.go ---
+
+package transpiler
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/v4rm4n/golden/transpiler/internal/odin"
+)
+
+// ── sync and sync/atomic ─────────────────────────────────────────────────────
+//
+// Methods are dispatched on the receiver's type, never on the method name
+// alone: wg.Wait() and server.Wait() are different calls.
+//
+//	sync.WaitGroup  → golden.WaitGroup     sync.Mutex  → sync.Mutex
+//	sync.RWMutex    → sync.RW_Mutex        sync.Once   → golden.Once
+//	sync.Cond       → golden.Cond          sync.Pool   → golden.Sync_Pool
+//	sync.Map        → golden.Sync_Map(K, V), K and V taken from Store calls
+//	atomic.Int64    → golden.Atomic(i64)   atomic.Pointer[T] → golden.Atomic(^T)
+//
+// Waiting operations go through the runtime so the scheduler and the
+// deadlock detector see them; the rest call core:sync directly.
+
+const untypedSyncMap = "golden.Sync_Map(rawptr, rawptr)"
+
+func mapSyncType(name string) string {
+	switch name {
+	case "WaitGroup":
+		return "golden.WaitGroup"
+	case "Mutex":
+		return "sync.Mutex"
+	case "RWMutex":
+		return "sync.RW_Mutex"
+	case "Once":
+		return "golden.Once"
+	case "Cond":
+		return "golden.Cond"
+	case "Map":
+		return "golden.Sync_Map"
+	case "Pool":
+		return "golden.Sync_Pool"
+	}
+	return name
+}
+
+func mapAtomicType(name string) string {
+	switch name {
+	case "Int32":
+		return "golden.Atomic(i32)"
+	case "Int64":
+		return "golden.Atomic(i64)"
+	case "Uint32":
+		return "golden.Atomic(u32)"
+	case "Uint64":
+		return "golden.Atomic(u64)"
+	case "Uintptr":
+		return "golden.Atomic(uintptr)"
+	case "Bool":
+		return "golden.Atomic(b8)"
+	}
+	return "atomic." + name
+}
+
+// isSharedSyncType reports whether goroutines share a variable of type t
+// by pointer instead of copying it.
+func isSharedSyncType(t string) bool {
+	switch t {
+	case "golden.WaitGroup", "sync.Mutex", "sync.RW_Mutex", "golden.Once", "golden.Sync_Pool":
+		return true
+	}
+	return strings.HasPrefix(t, "golden.Sync_Map(") || strings.HasPrefix(t, "golden.Atomic(")
+}
+
+// hasSyncFields reports whether a struct holds sync values, directly or
+// through nested struct fields, so copying it would split their state.
+func hasSyncFields(structName string, res *Resolver) bool {
+	return hasSyncFieldsSeen(structName, map[string]bool{}, res)
+}
+
+func hasSyncFieldsSeen(structName string, seen map[string]bool, res *Resolver) bool {
+	if seen[structName] {
+		return false
+	}
+	seen[structName] = true
+	for _, t := range res.structFields[structName] {
+		if isSharedSyncType(t) || hasSyncFieldsSeen(t, seen, res) {
+			return true
+		}
+	}
+	return false
+}
+
+// registerSyncMaps infers each sync.Map's key and value types from the
+// first Store/LoadOrStore call on it whose arguments have a known type.
+func registerSyncMaps(f *ast.File, res *Resolver) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || (sel.Sel.Name != "Store" && sel.Sel.Name != "LoadOrStore") {
+			return true
+		}
+		name := ""
+		switch x := sel.X.(type) {
+		case *ast.Ident:
+			name = x.Name
+		case *ast.SelectorExpr:
+			name = x.Sel.Name
+		}
+		if name == "" || res.syncMapTypes[name] != "" {
+			return true
+		}
+		k, v := literalType(call.Args[0], res), literalType(call.Args[1], res)
+		if k != "" && v != "" {
+			res.syncMapTypes[name] = fmt.Sprintf("golden.Sync_Map(%s, %s)", k, v)
+		}
+		return true
+	})
+}
+
+// literalType types an expression without a resolver: literals only.
+func literalType(expr ast.Expr, res *Resolver) string {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.INT:
+			return "int"
+		case token.FLOAT:
+			return "f64"
+		case token.STRING:
+			return "string"
+		case token.CHAR:
+			return "rune"
+		}
+	case *ast.CompositeLit:
+		return mapType(e.Type, res)
+	case *ast.UnaryExpr:
+		if lit, ok := e.X.(*ast.CompositeLit); ok && e.Op == token.AND {
+			return "^" + mapType(lit.Type, res)
+		}
+	}
+	return ""
+}
+
+// syncMapType completes golden.Sync_Map for the variable or field name.
+func syncMapType(t, name string, res *Resolver) string {
+	if t != "golden.Sync_Map" {
+		return t
+	}
+	if typed, ok := res.syncMapTypes[name]; ok {
+		return typed
+	}
+	return untypedSyncMap
+}
+
+// recvType returns the Odin type of a method receiver, without pointers.
+func recvType(x ast.Expr, res *Resolver) string {
+	switch e := x.(type) {
+	case *ast.Ident:
+		if sym, ok := res.Lookup(e.Name); ok {
+			return strings.TrimPrefix(sym.GoType, "^")
+		}
+	case *ast.SelectorExpr:
+		return strings.TrimPrefix(res.structFields[exprStructType(e.X, res)][e.Sel.Name], "^")
+	case *ast.ParenExpr:
+		return recvType(e.X, res)
+	case *ast.StarExpr:
+		return recvType(e.X, res)
+	case *ast.CallExpr:
+		return strings.TrimPrefix(stdCallType(e, res), "^")
+	}
+	return ""
+}
+
+// recvPtr returns a pointer to the receiver, taking its address if needed.
+func recvPtr(x ast.Expr, res *Resolver) odin.Expr {
+	recv := translateExpr(x, res)
+	switch e := x.(type) {
+	case *ast.Ident:
+		if sym, ok := res.Lookup(e.Name); ok && (isPointerType(sym.GoType) || sym.Strategy == AllocArena) {
+			return recv
+		}
+	case *ast.SelectorExpr:
+		if isPointerType(borrowedType(res.structFields[exprStructType(e.X, res)][e.Sel.Name])) {
+			return recv
+		}
+	}
+	return odin.AddrOf(recv)
+}
+
+// translateSyncMethod handles a method call on a sync or atomic value.
+func translateSyncMethod(sel *ast.SelectorExpr, call *ast.CallExpr, res *Resolver) (odin.Expr, bool) {
+	t := recvType(sel.X, res)
+	if t == "" {
+		return nil, false
+	}
+	p := recvPtr(sel.X, res)
+	args := append([]odin.Expr{p}, callArgs(call, res)...)
+	emit := func(proc string) (odin.Expr, bool) {
+		if strings.HasPrefix(proc, "sync.") {
+			res.CoreImports["sync"] = true
+		}
+		return odin.NewCall(proc, args...), true
+	}
+
+	method := sel.Sel.Name
+	switch {
+	case t == "golden.WaitGroup":
+		switch method {
+		case "Add":
+			return emit("golden.wg_add")
+		case "Done":
+			return emit("golden.wg_done")
+		case "Wait":
+			return emit("golden.wg_wait")
+		}
+	case t == "sync.Mutex":
+		switch method {
+		case "Lock":
+			return emit("golden.mutex_lock")
+		case "Unlock":
+			return emit("golden.mutex_unlock")
+		case "TryLock":
+			return emit("golden.mutex_try_lock")
+		}
+	case t == "sync.RW_Mutex":
+		switch method {
+		case "Lock":
+			return emit("golden.rw_lock")
+		case "Unlock":
+			return emit("golden.rw_unlock")
+		case "RLock":
+			return emit("golden.rw_rlock")
+		case "RUnlock":
+			return emit("golden.rw_runlock")
+		case "TryLock":
+			return emit("sync.rw_mutex_try_lock")
+		case "TryRLock":
+			return emit("sync.rw_mutex_try_shared_lock")
+		}
+	case t == "golden.Once":
+		if method == "Do" {
+			return emit("golden.once_do")
+		}
+	case t == "golden.Cond":
+		switch method {
+		case "Wait":
+			return emit("golden.cond_wait")
+		case "Signal":
+			return emit("golden.cond_signal")
+		case "Broadcast":
+			return emit("golden.cond_broadcast")
+		}
+	case t == "golden.Sync_Pool":
+		switch method {
+		case "Get":
+			return emit("golden.sync_pool_get")
+		case "Put":
+			return emit("golden.sync_pool_put")
+		}
+	case strings.HasPrefix(t, "golden.Sync_Map("):
+		switch method {
+		case "Store", "Load", "LoadOrStore", "LoadAndDelete", "Delete":
+			return emit("golden.sync_map_" + toSnakeCase(method))
+		}
+	case strings.HasPrefix(t, "golden.Atomic("):
+		// Typed atomics operate on their value field
+		args[0] = odin.AddrOf(odin.FieldOf(translateExpr(sel.X, res), "v"))
+		if proc, ok := atomicProc(method); ok {
+			return emit(proc)
+		}
+	}
+	return nil, false
+}
+
+// atomicProc maps an atomic operation name (Add, Load, CompareAndSwap...).
+func atomicProc(op string) (string, bool) {
+	switch op {
+	case "Add":
+		return "golden.atomic_add", true
+	case "Load":
+		return "sync.atomic_load", true
+	case "Store":
+		return "sync.atomic_store", true
+	case "Swap":
+		return "sync.atomic_exchange", true
+	case "CompareAndSwap":
+		return "golden.atomic_cas", true
+	}
+	return "", false
+}
+
+// translateSyncFunc handles package-level sync and sync/atomic calls:
+// sync.NewCond and atomic.AddInt64 / LoadPointer / CompareAndSwapUint32...
+func translateSyncFunc(pkgPath, name string, call *ast.CallExpr, res *Resolver) (odin.Expr, bool) {
+	args := callArgs(call, res)
+	switch pkgPath {
+	case "sync":
+		if name == "NewCond" {
+			return odin.NewCall("golden.cond_new", args...), true
+		}
+	case "sync/atomic":
+		for _, op := range []string{"CompareAndSwap", "Add", "Load", "Store", "Swap"} {
+			if !strings.HasPrefix(name, op) {
+				continue
+			}
+			proc, _ := atomicProc(op)
+			if strings.HasPrefix(proc, "sync.") {
+				res.CoreImports["sync"] = true
+			}
+			return odin.NewCall(proc, args...), true
+		}
+	}
+	return nil, false
+}
+
+// translateSyncStmt handles statement-level calls that take a func literal
+// and are inlined, so the literal's captures keep working:
+//
+//	once.Do(func() { ... })         → labelled `if golden.once_begin(&once)`
+//	m.Range(func(k, v any) bool {})  → labelled loop over a snapshot
+//
+// A `return` in the literal leaves the label instead of the function.
+func translateSyncStmt(call *ast.CallExpr, res *Resolver) ([]odin.Stmt, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil, false
+	}
+	fn, ok := call.Args[0].(*ast.FuncLit)
+	if !ok {
+		return nil, false
+	}
+	t := recvType(sel.X, res)
+	label := posName("_"+strings.ToLower(sel.Sel.Name), call.Pos(), res)
+	p := recvPtr(sel.X, res)
+
+	var out odin.Stmt
+	var body *[]odin.Stmt
+	res.EnterScope()
+	defer res.ExitScope()
+	switch {
+	case t == "golden.Once" && sel.Sel.Name == "Do":
+		rewriteReturns(fn.Body, func(*ast.ReturnStmt) []ast.Stmt {
+			return []ast.Stmt{branch(token.BREAK, label)}
+		})
+		once := &odin.If{Label: label, Cond: odin.NewCall("golden.once_begin", p)}
+		once.Body = []odin.Stmt{odin.DeferCall("golden.once_end", p)}
+		out, body = once, &once.Body
+
+	case strings.HasPrefix(t, "golden.Sync_Map(") && sel.Sel.Name == "Range":
+		k, v := "_", "_"
+		kt, vt := syncMapParams(t)
+		names := funcLitNames(fn)
+		if len(names) == 2 {
+			k, v = names[0], names[1]
+		}
+		res.Define(k, &Symbol{Name: k, GoType: kt})
+		res.Define(v, &Symbol{Name: v, GoType: vt})
+		rewriteReturns(fn.Body, func(r *ast.ReturnStmt) []ast.Stmt {
+			if len(r.Results) == 1 {
+				if ident, ok := r.Results[0].(*ast.Ident); ok && ident.Name == "true" {
+					return []ast.Stmt{branch(token.CONTINUE, label)}
+				}
+				if ident, ok := r.Results[0].(*ast.Ident); ok && ident.Name == "false" {
+					return []ast.Stmt{branch(token.BREAK, label)}
+				}
+				// return cond  →  if !(cond) { break }; continue
+				stop := &ast.IfStmt{
+					Cond: &ast.UnaryExpr{Op: token.NOT, X: &ast.ParenExpr{X: r.Results[0]}},
+					Body: &ast.BlockStmt{List: []ast.Stmt{branch(token.BREAK, label)}},
+				}
+				return []ast.Stmt{stop, branch(token.CONTINUE, label)}
+			}
+			return []ast.Stmt{branch(token.CONTINUE, label)}
+		})
+		loop := &odin.ForIn{
+			Label: label,
+			Vars:  []odin.Expr{odin.NewIdent(k), odin.NewIdent(v)},
+			X:     odin.NewCall("golden.sync_map_snapshot", p),
+		}
+		out, body = loop, &loop.Body
+
+	default:
+		return nil, false
+	}
+	*body = append(*body, translateBody(fn.Body.List, res)...)
+	return []odin.Stmt{out}, true
+}
+
+// syncMapParams splits golden.Sync_Map(K, V) into K and V.
+func syncMapParams(t string) (string, string) {
+	inner := strings.TrimSuffix(strings.TrimPrefix(t, "golden.Sync_Map("), ")")
+	k, v, _ := strings.Cut(inner, ", ")
+	return k, v
+}
+
+func funcLitNames(fn *ast.FuncLit) []string {
+	var names []string
+	for _, field := range fn.Type.Params.List {
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+func branch(tok token.Token, label string) *ast.BranchStmt {
+	return &ast.BranchStmt{Tok: tok, Label: ast.NewIdent(label)}
+}
+
+// rewriteReturns replaces every return statement in body, outside nested
+// func literals, with the statements produced by replace.
+func rewriteReturns(body *ast.BlockStmt, replace func(*ast.ReturnStmt) []ast.Stmt) {
+	var rewrite func(list []ast.Stmt) []ast.Stmt
+	rewrite = func(list []ast.Stmt) []ast.Stmt {
+		var out []ast.Stmt
+		for _, stmt := range list {
+			switch s := stmt.(type) {
+			case *ast.ReturnStmt:
+				out = append(out, replace(s)...)
+				continue
+			case *ast.BlockStmt:
+				s.List = rewrite(s.List)
+			case *ast.IfStmt:
+				s.Body.List = rewrite(s.Body.List)
+				for el := s.Else; el != nil; {
+					switch e := el.(type) {
+					case *ast.BlockStmt:
+						e.List = rewrite(e.List)
+						el = nil
+					case *ast.IfStmt:
+						e.Body.List = rewrite(e.Body.List)
+						el = e.Else
+					default:
+						el = nil
+					}
+				}
+			case *ast.ForStmt:
+				s.Body.List = rewrite(s.Body.List)
+			case *ast.RangeStmt:
+				s.Body.List = rewrite(s.Body.List)
+			}
+			out = append(out, stmt)
+		}
+		return out
+	}
+	body.List = rewrite(body.List)
+}
@@ -0,0 +1,57 @@
+// --- golden/transpiler/sync_test.go ---
+
+package transpiler
+
+import "testing"
+
+func TestSync(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+type Cache struct {
+	mu sync.RWMutex
+	m  map[string]int
+}
+
+func (c *Cache) Get(k string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.m[k]
+}
+
+func main() {
+	var once sync.Once
+	var hits atomic.Int64
+	var n int32
+	var mu sync.Mutex
+	cond := sync.NewCond(&mu)
+	hits.Add(1)
+	atomic.AddInt32(&n, 2)
+	once.Do(func() { fmt.Println(hits.Load()) })
+	mu.Lock()
+	cond.Broadcast()
+	mu.Unlock()
+}
+`)
+	wantLines(t, code,
+		"mu: sync.RW_Mutex,",
+		"golden.rw_rlock(&c.mu)",
+		"defer golden.rw_runlock(&c.mu)",
+		"once: golden.Once",
+		"hits: golden.Atomic(i64)",
+		"cond := golden.cond_new(&mu)",
+		"defer free(cond)",
+		"golden.atomic_add(&hits.v, 1)",
+		"golden.atomic_add(&n, 2)",
+		"_do_main_28_2: if golden.once_begin(&once) {",
+		"defer golden.once_end(&once)",
+		"golden.mutex_lock(&mu)",
+		"golden.cond_broadcast(cond)",
+		"golden.mutex_unlock(&mu)",
+	)
+}
@@ -0,0 +1,194 @@
+// --- golden/transpiler/time.go ---
+
+package transpiler
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/v4rm4n/golden/transpiler/internal/odin"
+)
+
+// ── time ─────────────────────────────────────────────────────────────────────
+//
+// Go's Duration is int64 nanoseconds, exactly like Odin's time.Duration, and
+// the unit constants share their names, so `2 * time.Second` and
+// `time.Duration(n) * time.Millisecond` pass through untouched. Timers and
+// tickers are runtime channels fed by golden's timer thread, released when
+// the variable holding them goes out of scope; Sleep parks the goroutine
+// through the scheduler.
+//
+//	time.Time  → time.Time      *time.Timer, *time.Ticker → ^golden.Timer
+
+func mapTimeType(name string) string {
+	switch name {
+	case "Timer", "Ticker":
+		return "golden.Timer"
+	}
+	return "time." + name
+}
+
+// translateTimeFunc handles package-level time calls.
+func translateTimeFunc(name string, call *ast.CallExpr, res *Resolver) (odin.Expr, bool) {
+	args := callArgs(call, res)
+	switch name {
+	case "Now":
+		return odin.NewCall("time.now"), true
+	case "Since":
+		return odin.NewCall("time.since", args...), true
+	case "Until":
+		return odin.NewCall("time.diff", append([]odin.Expr{odin.NewCall("time.now")}, args...)...), true
+	case "Unix":
+		return odin.NewCall("time.unix", args...), true
+	case "Duration":
+		return odin.NewCall("time.Duration", args...), true
+	case "Sleep":
+		return odin.NewCall("golden.sleep", args...), true
+	case "After":
+		return odin.NewCall("golden.time_after", args...), true
+	case "Tick":
+		return odin.FieldOf(odin.NewCall("golden.ticker_new", args...), "C"), true
+	case "NewTimer":
+		return odin.NewCall("golden.timer_new", args...), true
+	case "NewTicker":
+		return odin.NewCall("golden.ticker_new", args...), true
+	}
+	return nil, false
+}
+
+// translateTimeMethod handles methods on Time, Duration, Timer and Ticker.
+func translateTimeMethod(sel *ast.SelectorExpr, call *ast.CallExpr, res *Resolver) (odin.Expr, bool) {
+	recv := translateExpr(sel.X, res)
+	args := callArgs(call, res)
+	// t.Before(u) and friends compare time.diff(u, t) with zero
+	diff := func(op string) (odin.Expr, bool) {
+		return &odin.Binary{X: odin.NewCall("time.diff", append(args, recv)...), Op: op, Y: odin.IntLit(0)}, true
+	}
+
+	switch recvType(sel.X, res) {
+	case "time.Time":
+		switch sel.Sel.Name {
+		case "Sub":
+			return odin.NewCall("time.diff", append(args, recv)...), true
+		case "Add":
+			return odin.NewCall("time.time_add", append([]odin.Expr{recv}, args...)...), true
+		case "Before":
+			return diff("<")
+		case "After":
+			return diff(">")
+		case "Equal":
+			return diff("==")
+		case "Unix":
+			return odin.NewCall("time.time_to_unix", recv), true
+		case "UnixMilli":
+			return &odin.Binary{X: odin.NewCall("time.time_to_unix_nano", recv), Op: "/", Y: &odin.BasicLit{Value: "1e6"}}, true
+		case "UnixNano":
+			return odin.NewCall("time.time_to_unix_nano", recv), true
+		}
+	case "time.Duration":
+		switch sel.Sel.Name {
+		case "Hours", "Minutes", "Seconds":
+			return odin.NewCall("time.duration_"+strings.ToLower(sel.Sel.Name), recv), true
+		case "Milliseconds", "Microseconds":
+			return odin.NewCall("i64", odin.NewCall("time.duration_"+strings.ToLower(sel.Sel.Name), recv)), true
+		case "Nanoseconds":
+			return odin.NewCall("i64", recv), true
+		case "String":
+			return odin.NewCall("golden.duration_string", recv), true
+		}
+	case "golden.Timer":
+		switch sel.Sel.Name {
+		case "Stop":
+			return odin.NewCall("golden.timer_stop", recv), true
+		case "Reset":
+			return odin.NewCall("golden.timer_reset", append([]odin.Expr{recv}, args...)...), true
+		}
+	}
+	return nil, false
+}
+
+// timerDrops maps the calls that create timers to what hands them back to
+// the runtime once their handle goes out of scope.
+var timerDrops = map[string]string{
+	"time.NewTimer":  "golden.timer_detach",
+	"time.NewTicker": "golden.timer_detach",
+	"time.After":     "golden.time_after_done",
+}
+
+// timerDrop returns the call dropping `name := time.NewTimer(d)` and the
+// like at scope end, unless the handle outlives fn or a goroutine uses it.
+func timerDrop(name string, call *ast.CallExpr, fn ast.Node) (string, bool) {
+	drop, ok := timerDrops[exprToStrBasic(call.Fun)]
+	if !ok || fn == nil || movesOut(name, fn) {
+		return "", false
+	}
+	spawned := false
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if g, ok := n.(*ast.GoStmt); ok {
+			ast.Inspect(g, func(n ast.Node) bool {
+				if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+					spawned = true
+				}
+				return !spawned
+			})
+			return false
+		}
+		return !spawned
+	})
+	return drop, !spawned
+}
+
+// stdCallType is the Odin type of a standard library call's result, for
+// registering `x := pkg.F()` variables.
+func stdCallType(call *ast.CallExpr, res *Resolver) string {
+	if t := contextCallType(call, res); t != "" {
+		return t
+	}
+	switch exprToStrBasic(call.Fun) {
+	case "sync.NewCond":
+		return "^golden.Cond"
+	case "time.Now":
+		return "time.Time"
+	case "time.Since", "time.Until", "time.Duration":
+		return "time.Duration"
+	case "time.NewTimer", "time.NewTicker":
+		return "^golden.Timer"
+	case "time.After", "time.Tick":
+		return "^golden.Channel(time.Time)"
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && recvType(sel.X, res) == "time.Time" {
+		switch sel.Sel.Name {
+		case "Sub":
+			return "time.Duration"
+		case "Add":
+			return "time.Time"
+		}
+	}
+	return ""
+}
+
+// isDurationExpr reports whether x is a time.Duration: a unit constant,
+// a Duration value, or arithmetic with one (2 * time.Second).
+func isDurationExpr(x ast.Expr, res *Resolver) bool {
+	switch e := x.(type) {
+	case *ast.SelectorExpr:
+		switch exprToStrBasic(e) {
+		case "time.Nanosecond", "time.Microsecond", "time.Millisecond", "time.Second", "time.Minute", "time.Hour":
+			return true
+		}
+	case *ast.Ident:
+		sym, ok := res.Lookup(e.Name)
+		return ok && sym.GoType == "time.Duration"
+	case *ast.CallExpr:
+		return stdCallType(e, res) == "time.Duration"
+	case *ast.ParenExpr:
+		return isDurationExpr(e.X, res)
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.MUL, token.QUO, token.ADD, token.SUB, token.REM:
+			return isDurationExpr(e.X, res) || isDurationExpr(e.Y, res)
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+// --- golden/transpiler/time_test.go ---
+
+package transpiler
+
+import "testing"
+
+func TestTimeSleepTickerAndDuration(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func main() {
+	d := 2 * time.Millisecond
+	time.Sleep(d)
+	tk := time.NewTicker(time.Millisecond)
+	<-tk.C
+	tk.Stop()
+	start := time.Now()
+	fmt.Println(time.Since(start) < time.Second, d.String())
+}
+`)
+	wantLines(t, code,
+		"d := 2 * time.Millisecond",
+		"golden.sleep(d)",
+		"tk := golden.ticker_new(time.Millisecond)",
+		"defer golden.timer_detach(tk)",
+		"golden.chan_recv(tk.C)",
+		"golden.timer_stop(tk)",
+		"start := time.now()",
+		"fmt.println(time.since(start) < time.Second, golden.duration_string(d))",
+	)
+}
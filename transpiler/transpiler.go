@@ -0,0 +1,1512 @@
+// --- golden/transpiler/transpiler.go ---
+
+package transpiler
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/v4rm4n/golden/transpiler/internal/odin"
+)
+
+// ── Top-level processor ──────────────────────────────────────────────────────
+
+// Process translates f with the default Options, without positions or
+// diagnostics. New(...).Transpile is the full API.
+func Process(f *ast.File) string {
+	out, _, _ := Translate(nil, f, Options{})
+	return out
+}
+
+// Translate converts f using opts and maps every generated line back to
+// its Go position in fset. The map is also embedded in the output so the
+// runtime can report leaks and panics against the Go source. Whatever
+// couldn't be translated faithfully comes back as diagnostics.
+func Translate(fset *token.FileSet, f *ast.File, opts Options) (string, SourceMap, []Diagnostic) {
+	res := NewResolver()
+	res.File = f
+	res.Fset = fset
+	res.Opts = opts
+	res.diags = newDiagSink(fset)
+	res.PopulateImports(f)
+	registerSyncMaps(f, res)
+
+	// PASS 1: The Census (Global Symbol Registration & Method Tracking)
+	for _, decl := range f.Decls {
+		if fd, ok := decl.(*ast.FuncDecl); ok {
+			// Track if methods use pointer receivers
+			if fd.Recv != nil && len(fd.Recv.List) > 0 {
+				_, isPtr := fd.Recv.List[0].Type.(*ast.StarExpr)
+				res.methodIsPointer[fd.Name.Name] = isPtr
+			}
+			registerFuncParams(fd, res)
+			// Track return types for ARC routing
+			if fd.Type.Results != nil && len(fd.Type.Results.List) > 0 {
+				first := fd.Type.Results.List[0].Type
+				if star, ok := first.(*ast.StarExpr); ok {
+					res.funcReturnTypes[fd.Name.Name] = mapType(star.X, res)
+				}
+			}
+			// Register Function in Resolver
+			res.Define(fd.Name.Name, &Symbol{Name: fd.Name.Name, GoType: "proc", IsGlobal: true})
+
+		} else if gd, ok := decl.(*ast.GenDecl); ok {
+			for _, spec := range gd.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					res.Define(ts.Name.Name, &Symbol{Name: ts.Name.Name, GoType: "struct", IsGlobal: true})
+					registerStructFields(ts, res)
+				}
+			}
+		}
+	}
+
+	// PASS 1B: Ownership of returned slices, maps and strings.
+	// Repeat until stable so `return helper()` chains resolve.
+	for changed := true; changed; {
+		changed = false
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || res.funcReturnsOwned[fd.Name.Name] != "" {
+				continue
+			}
+			if retType, ok := ownedResult(fd, res); ok {
+				res.funcReturnsOwned[fd.Name.Name] = retType
+				changed = true
+			}
+		}
+	}
+
+	// PASS 2: The Alchemy (Translation)
+	file := &odin.File{Package: "main"}
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			file.Decls = append(file.Decls, handleStruct(d, res)...)
+		case *ast.FuncDecl:
+			file.Decls = append(file.Decls, handleFuncWithResolver(d, res))
+		}
+	}
+
+	// IMPORT CORE:MEM ALWAYS (since main uses it now)
+	imports := []odin.Import{{Path: "core:mem"}, {Path: "core:fmt"}}
+
+	if _, hasOs := res.Imports["os"]; hasOs {
+		imports = append(imports, odin.Import{Path: "core:os"}, odin.Import{Name: "golden_os", Path: "golden/runtime"})
+	} else if res.CoreImports["os"] {
+		imports = append(imports, odin.Import{Path: "core:os"})
+	}
+	if _, hasSync := res.Imports["sync"]; hasSync || res.CoreImports["sync"] {
+		imports = append(imports, odin.Import{Path: "core:sync"})
+	}
+	if _, hasTime := res.Imports["time"]; hasTime {
+		imports = append(imports, odin.Import{Path: "core:time"})
+	}
+	if res.CoreImports["strings"] {
+		imports = append(imports, odin.Import{Path: "core:strings"})
+	}
+	imports = append(imports, mappedImports(res)...)
+	// Imports go in before printing, so the source map's line numbers hold
+	file.Imports = uniqueImports(append(imports, odin.Import{Name: "golden", Path: "golden"}))
+
+	code, marks := odin.Print(file)
+	sm := sourceMap(marks, fset)
+	if len(sm) > 0 {
+		code += "\n" + odin.PrintStmts(sm.odinTable()...)
+	}
+	return code, sm, res.diags.sorted()
+}
+
+// ── Type Mapping ─────────────────────────────────────────────────────────────
+
+func mapType(expr ast.Expr, res *Resolver) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "int":
+			return "int"
+		case "int32":
+			return "i32"
+		case "int64":
+			return "i64"
+		case "uint":
+			return "uint"
+		case "string":
+			return "string"
+		case "bool":
+			return "b8"
+		case "float32":
+			return "f32"
+		case "float64":
+			return "f64"
+		case "byte":
+			return "byte"
+		case "rune":
+			return "rune"
+		case "error":
+			return "cstring"
+		default:
+			return t.Name
+		}
+	case *ast.StarExpr:
+		return "^" + mapType(t.X, res)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return fmt.Sprintf("[dynamic]%s", mapType(t.Elt, res))
+		}
+		return fmt.Sprintf("[%s]%s", exprToStrBasic(t.Len), mapType(t.Elt, res))
+	case *ast.MapType:
+		return fmt.Sprintf("map[%s]%s", mapType(t.Key, res), mapType(t.Value, res))
+	case *ast.SelectorExpr:
+		pkg := exprToStrBasic(t.X)
+		name := t.Sel.Name
+		switch pkg {
+		case "sync":
+			return mapSyncType(name)
+		case "atomic":
+			return mapAtomicType(name)
+		case "time":
+			return mapTimeType(name)
+		case "context":
+			return mapContextType(name)
+		}
+		return pkg + "." + name
+	case *ast.ChanType:
+		return fmt.Sprintf("^golden.Channel(%s)", mapType(t.Value, res))
+	case *ast.StructType:
+		// struct{} — signal channels, set maps
+		if t.Fields == nil || len(t.Fields.List) == 0 {
+			return "golden.Empty"
+		}
+	case *ast.IndexExpr:
+		// atomic.Pointer[T]
+		if exprToStrBasic(t.X) == "atomic.Pointer" {
+			return fmt.Sprintf("golden.Atomic(^%s)", mapType(t.Index, res))
+		}
+	}
+	if expr != nil {
+		res.report(expr.Pos(), Warning, CatType, nodeKind(expr), "unsupported type %s, emitted as rawptr", types.ExprString(expr))
+	}
+	return "rawptr"
+}
+
+func handleStruct(d *ast.GenDecl, res *Resolver) []odin.Stmt {
+	var decls []odin.Stmt
+	for _, spec := range d.Specs {
+		if v, ok := spec.(*ast.ValueSpec); ok {
+			res.report(v.Pos(), Error, CatDeclaration, "package-level "+d.Tok.String(), "package-level %s declarations are not supported", d.Tok)
+			continue
+		}
+		t, ok := spec.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+		st, ok := t.Type.(*ast.StructType)
+		if !ok {
+			// type ctxKey string → a distinct Odin type
+			if _, isIface := t.Type.(*ast.InterfaceType); isIface {
+				res.report(t.Pos(), Error, CatDeclaration, "interface declaration", "interface type %s is not supported", t.Name.Name)
+				continue
+			}
+			decls = append(decls, markPos(t.Pos(), &odin.DistinctDecl{Name: t.Name.Name, Type: mapType(t.Type, res)}))
+			continue
+		}
+
+		decl := &odin.StructDecl{Name: t.Name.Name}
+		for _, field := range st.Fields.List {
+			typeName := fieldType(field, res)
+			for _, name := range field.Names {
+				decl.Fields = append(decl.Fields, odin.Field{Name: name.Name, Type: typeName})
+			}
+		}
+		decls = append(decls, markPos(t.Pos(), decl))
+		if weak, strong := dropFieldsOf(t, res); len(weak)+len(strong) > 0 {
+			decls = append(decls, dropProc(t.Name.Name, weak, strong))
+		}
+	}
+	return decls
+}
+
+// ── Function Handler ─────────────────────────────────────────────────────────
+
+func handleFuncWithResolver(d *ast.FuncDecl, res *Resolver) odin.Stmt {
+	res.EnterScope()
+	defer res.ExitScope()
+
+	var params []odin.Field
+	funcName := d.Name.Name
+	needsFrame := false
+	res.RaceShared = nil
+	if res.Opts.Race && d.Body != nil {
+		res.RaceShared = raceCandidates(d.Body)
+	}
+
+	// Handle Receiver
+	if d.Recv != nil && len(d.Recv.List) > 0 {
+		recv := d.Recv.List[0]
+		recvType := mapType(recv.Type, res)
+		recvName := "self"
+		if len(recv.Names) > 0 {
+			recvName = recv.Names[0].Name
+		}
+		structName := strings.TrimPrefix(recvType, "^")
+		funcName = fmt.Sprintf("%s_%s", structName, d.Name.Name)
+		params = append(params, odin.Field{Name: recvName, Type: recvType})
+		res.Define(recvName, &Symbol{Name: recvName, GoType: recvType, Strategy: AllocNone})
+	}
+
+	// Handle Parameters
+	if d.Type.Params != nil {
+		for _, field := range d.Type.Params.List {
+			pType := mapType(field.Type, res)
+			for _, pName := range field.Names {
+				strategy := AllocNone
+				if _, ok := field.Type.(*ast.StarExpr); ok {
+					strategy = AllocArena
+					needsFrame = true
+				}
+				params = append(params, odin.Field{Name: pName.Name, Type: pType})
+				res.Define(pName.Name, &Symbol{Name: pName.Name, GoType: pType, Strategy: strategy, IsParam: true})
+			}
+		}
+	}
+
+	if d.Body != nil && prepareBody(d.Body, res) {
+		needsFrame = true
+	}
+
+	var rets []string
+	res.ReturnsArc = false
+	if d.Type.Results != nil {
+		for _, r := range d.Type.Results.List {
+			if star, ok := r.Type.(*ast.StarExpr); ok {
+				innerType := mapType(star.X, res)
+
+				// Scan current scope to see if we mapped an escaping var to ARC
+				hasEscapingArc := false
+				for _, sym := range res.Current.Symbols {
+					if sym.Strategy == AllocARC && sym.GoType == innerType {
+						hasEscapingArc = true
+						break
+					}
+				}
+
+				if hasEscapingArc {
+					res.ReturnsArc = true
+					rets = append(rets, "golden.Arc("+innerType+")")
+				} else {
+					rets = append(rets, "^"+innerType)
+				}
+			} else {
+				rets = append(rets, mapType(r.Type, res))
+			}
+		}
+	}
+
+	proc := &odin.ProcDecl{Name: funcName, Params: params, Results: rets}
+	if d.Body != nil {
+		if d.Name.Name == "main" {
+			proc.Body = append(proc.Body, mainPrologue(res)...)
+			// One worker per CPU core
+			if res.Opts.Workers > 0 {
+				proc.Body = append(proc.Body, odin.CallStmt("golden.pool_start", odin.IntLit(res.Opts.Workers)))
+			} else {
+				proc.Body = append(proc.Body, odin.CallStmt("golden.pool_start"))
+			}
+			proc.Body = append(proc.Body, odin.DeferCall("golden.pool_stop"))
+		}
+		if needsFrame {
+			proc.Body = append(proc.Body, frameBegin()...)
+		}
+		proc.Body = append(proc.Body, translateBody(d.Body.List, res)...)
+	}
+	return markPos(d.Pos(), proc)
+}
+
+// prepareBody registers a function body's locals before translation:
+// escape analysis routes &T{} to the frame arena or ARC, and slices, maps
+// and heap strings get the same split. It reports whether the body needs
+// a _frame.
+func prepareBody(body *ast.BlockStmt, res *Resolver) bool {
+	needsFrame := false
+	escapes := AnalyzeFunc(body)
+	heapVars := make(map[string]heapKind)
+	for _, stmt := range body.List {
+		// `var xs []T` grows through append — a heap value too
+		for _, vs := range zeroSliceDecl(stmt) {
+			for _, ident := range vs.Names {
+				heapVars[ident.Name] = heapZeroSlice
+				res.Define(ident.Name, &Symbol{Name: ident.Name, GoType: mapType(vs.Type, res)})
+			}
+		}
+
+		if assign, ok := stmt.(*ast.AssignStmt); ok {
+			if len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+				continue
+			}
+			varName := exprToStrBasic(assign.Lhs[0])
+
+			// D. Handle slices, maps and heap strings (see heap.go)
+			if _, isIdent := assign.Lhs[0].(*ast.Ident); isIdent && assign.Tok == token.DEFINE {
+				if kind := assignHeapKind(varName, assign.Rhs[0], body, res); kind != heapNone {
+					heapVars[varName] = kind
+					res.Define(varName, &Symbol{Name: varName, GoType: heapGoType(assign.Rhs[0], kind, res)})
+					continue
+				}
+				if isStringExpr(assign.Rhs[0], res) {
+					res.Define(varName, &Symbol{Name: varName, GoType: "string", Strategy: AllocNone})
+				}
+			}
+
+			// A. Handle &Struct{} allocations
+			if unary, ok := assign.Rhs[0].(*ast.UnaryExpr); ok && unary.Op == token.AND {
+				if lit, ok := unary.X.(*ast.CompositeLit); ok {
+					strat := AllocArena
+					if escapes[varName] {
+						strat = AllocARC
+					}
+					goType := mapType(lit.Type, res)
+					if strat == AllocArena {
+						needsFrame = true
+						goType = "^" + goType // frame_new hands back a pointer
+					}
+					res.Define(varName, &Symbol{Name: varName, GoType: goType, Strategy: strat})
+				}
+				continue
+			}
+
+			// B. Handle make() calls (like channels and slices)
+			if call, ok := assign.Rhs[0].(*ast.CallExpr); ok {
+				funcName := exprToStrBasic(call.Fun)
+				if funcName == "make" && len(call.Args) > 0 {
+					if chanType, isChan := call.Args[0].(*ast.ChanType); isChan {
+						// Register the channel in the resolver!
+						res.Define(varName, &Symbol{
+							Name:     varName,
+							GoType:   "^golden.Channel(" + mapType(chanType.Value, res) + ")",
+							Strategy: AllocNone,
+						})
+						continue
+					}
+				}
+				// Handle normal function calls (check return type map)
+				if retTypeName, ok := res.funcReturnTypes[funcName]; ok {
+					res.Define(varName, &Symbol{Name: varName, GoType: retTypeName, Strategy: AllocARC})
+					continue
+				}
+			}
+
+			// C. Handle standard Struct{} initialization (worker := Worker{})
+			if lit, ok := assign.Rhs[0].(*ast.CompositeLit); ok {
+				res.Define(varName, &Symbol{
+					Name:     varName,
+					GoType:   mapType(lit.Type, res),
+					Strategy: AllocNone,
+				})
+				continue
+			}
+		}
+	}
+
+	// Heap values get the same arena-vs-owned split as &T{}
+	heapNames := make(map[string]bool)
+	for name := range heapVars {
+		heapNames[name] = true
+	}
+	heapEscapes := AnalyzeVars(body, heapNames)
+	for name, kind := range heapVars {
+		sym, _ := res.Lookup(name)
+		sym.Strategy = heapStrategy(kind, heapEscapes[name])
+		if sym.Strategy == AllocArena {
+			needsFrame = true
+		}
+	}
+	return needsFrame
+}
+
+// mainPrologue sets up memory checking for the selected mode.
+func mainPrologue(res *Resolver) []odin.Stmt {
+	var out []odin.Stmt
+	track := odin.NewIdent("track")
+	mode := res.Opts.Memory
+	if mode != MemRelease {
+		// INJECT ODIN'S TRACKING ALLOCATOR
+		allocations := odin.FieldOf(track, "allocation_map")
+		badFrees := odin.FieldOf(track, "bad_free_array")
+		leaked := &odin.Binary{X: odin.NewCall("len", allocations), Op: ">", Y: odin.IntLit(0)}
+		badFreed := &odin.Binary{X: odin.NewCall("len", badFrees), Op: ">", Y: odin.IntLit(0)}
+		report := []odin.Stmt{
+			&odin.If{Cond: leaked, Body: []odin.Stmt{
+				odin.CallStmt("fmt.eprintf", odin.StringLit("\n=== MEMORY LEAK DETECTED: %v allocations not freed ===\n"), odin.NewCall("len", allocations)),
+				&odin.ForIn{Vars: []odin.Expr{odin.NewIdent("_"), odin.NewIdent("entry")}, X: allocations, Body: []odin.Stmt{
+					odin.CallStmt("fmt.eprintf", odin.StringLit("- %v bytes @ %v\n"),
+						odin.FieldOf(odin.NewIdent("entry"), "size"), odin.NewCall("golden.source_pos", odin.FieldOf(odin.NewIdent("entry"), "location"))),
+				}},
+			}},
+			&odin.If{Cond: badFreed, Body: []odin.Stmt{
+				odin.CallStmt("fmt.eprintf", odin.StringLit("\n=== BAD FREES DETECTED: %v incorrect frees ===\n"), odin.NewCall("len", badFrees)),
+				&odin.ForIn{Vars: []odin.Expr{odin.NewIdent("entry")}, X: badFrees, Body: []odin.Stmt{
+					odin.CallStmt("fmt.eprintf", odin.StringLit("- %p @ %v\n"),
+						odin.FieldOf(odin.NewIdent("entry"), "memory"), odin.NewCall("golden.source_pos", odin.FieldOf(odin.NewIdent("entry"), "location"))),
+				}},
+			}},
+		}
+		if mode == MemStrict {
+			// CI gate: any leak or bad free fails the run
+			res.CoreImports["os"] = true
+			report = append(report, &odin.If{
+				Cond: &odin.Binary{X: leaked, Op: "||", Y: badFreed},
+				Body: []odin.Stmt{odin.CallStmt("os.exit", odin.IntLit(1))},
+			})
+		}
+		out = append(out,
+			&odin.VarDecl{Name: "track", Type: "mem.Tracking_Allocator"},
+			odin.CallStmt("mem.tracking_allocator_init", odin.AddrOf(track), odin.NewIdent("context.allocator")),
+			odin.NewAssign(odin.NewIdent("context.allocator"), "=", odin.NewCall("mem.tracking_allocator", odin.AddrOf(track))),
+			odin.DeferCall("mem.tracking_allocator_destroy", odin.AddrOf(track)),
+			&odin.Defer{Stmt: &odin.Block{Body: report}},
+			// Poison freed ARC data and trap refcount underflow
+			odin.NewAssign(odin.NewIdent("golden.debug_memory"), "=", odin.NewIdent("true")),
+			&odin.Blank{},
+		)
+	}
+
+	// Report panics and leaks against the Go source
+	if res.Fset != nil {
+		out = append(out, odin.CallStmt("golden.source_map_install", &odin.Slice{X: odin.NewIdent("_golden_source_map")}))
+	}
+	out = append(out, odin.NewAssign(odin.NewIdent("context.assertion_failure_proc"), "=", odin.NewIdent("golden.assertion_failure")), &odin.Blank{})
+	if res.Opts.Race {
+		out = append(out, odin.NewAssign(odin.NewIdent("golden.race_enabled"), "=", odin.NewIdent("true")), &odin.Blank{})
+	}
+	if res.Opts.Deterministic {
+		out = append(out, odin.CallStmt("golden.sched_deterministic", &odin.BasicLit{Value: strconv.FormatUint(res.Opts.Seed, 10)}), &odin.Blank{})
+	}
+	return out
+}
+
+// mappedImports imports the Odin packages Options.Packages maps this
+// file's imports to, under the names the Go code uses.
+func mappedImports(res *Resolver) []odin.Import {
+	var imports []odin.Import
+	for name, path := range res.Imports {
+		if pkg, ok := res.Opts.Packages[path]; ok {
+			imports = append(imports, odin.Import{Name: name, Path: pkg})
+		}
+	}
+	sort.Slice(imports, func(i, j int) bool { return imports[i].Name < imports[j].Name })
+	return imports
+}
+
+// uniqueImports drops repeated imports, such as a merged package's files
+// importing the same package, keeping the first of each.
+func uniqueImports(imports []odin.Import) []odin.Import {
+	seen := make(map[odin.Import]bool)
+	var out []odin.Import
+	for _, imp := range imports {
+		if !seen[imp] {
+			seen[imp] = true
+			out = append(out, imp)
+		}
+	}
+	return out
+}
+
+// ── Statement Translation ────────────────────────────────────────────────────
+
+// translateBody translates a statement list. Nested blocks are flattened
+// into it, each still in a scope of its own, so their defers run when the
+// function returns as in Go; one shadowing an outer variable stays a block,
+// as Odin won't redeclare a name in the same scope.
+func translateBody(stmts []ast.Stmt, res *Resolver) []odin.Stmt {
+	var out []odin.Stmt
+	for _, stmt := range stmts {
+		if block, ok := stmt.(*ast.BlockStmt); ok && !keepsBlock(block, res) {
+			res.EnterScope()
+			out = append(out, translateBody(block.List, res)...)
+			res.ExitScope()
+			continue
+		}
+		hooks := raceHooks(stmt, res) // before translation defines new names
+		hooks = append(hooks, hoistOwnedCalls(stmt, res)...)
+		out = append(out, markFirst(stmt.Pos(), append(hooks, translateStmtWithResolver(stmt, res)...))...)
+	}
+	return out
+}
+
+// keepsBlock reports whether a nested block redeclares a visible name at
+// its top level and defers nothing there.
+func keepsBlock(block *ast.BlockStmt, res *Resolver) bool {
+	shadows := false
+	for _, stmt := range block.List {
+		switch s := stmt.(type) {
+		case *ast.DeferStmt:
+			return false
+		case *ast.AssignStmt:
+			if s.Tok != token.DEFINE {
+				continue
+			}
+			for _, lhs := range s.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					_, visible := res.Lookup(ident.Name)
+					shadows = shadows || visible
+				}
+			}
+		case *ast.DeclStmt:
+			for _, spec := range s.Decl.(*ast.GenDecl).Specs {
+				if vs, ok := spec.(*ast.ValueSpec); ok {
+					for _, ident := range vs.Names {
+						_, visible := res.Lookup(ident.Name)
+						shadows = shadows || visible
+					}
+				}
+			}
+		}
+	}
+	return shadows
+}
+
+func translateStmtWithResolver(stmt ast.Stmt, res *Resolver) []odin.Stmt {
+	switch s := stmt.(type) {
+
+	case *ast.AssignStmt:
+		// ctx, cancel := context.WithCancel(parent): ctx owns the new node
+		if out, ok := translateContextAssign(s, res); ok {
+			return out
+		}
+
+		// Explicitly register short-variable declarations (:=) so closures can capture them
+		if s.Tok == token.DEFINE {
+			for i, l := range s.Lhs {
+				if ident, ok := l.(*ast.Ident); ok {
+					if _, exists := res.Current.Symbols[ident.Name]; !exists {
+						goType := "int" // Default fallback
+						if i < len(s.Rhs) {
+							if isStringExpr(s.Rhs[i], res) {
+								goType = "string"
+							} else if lit, ok := s.Rhs[i].(*ast.BasicLit); ok {
+								if lit.Kind == token.FLOAT {
+									goType = "f64"
+								}
+							} else if rhsId, ok := s.Rhs[i].(*ast.Ident); ok {
+								if sym, ok := res.Lookup(rhsId.Name); ok {
+									goType = sym.GoType
+								}
+							} else if call, ok := s.Rhs[i].(*ast.CallExpr); ok {
+								funcName := exprToStrBasic(call.Fun)
+								if retType, ok := res.funcReturnTypes[funcName]; ok {
+									goType = retType
+								} else if t := stdCallType(call, res); t != "" {
+									goType = t
+								}
+							} else if ta, ok := s.Rhs[i].(*ast.TypeAssertExpr); ok && ta.Type != nil {
+								goType = mapType(ta.Type, res)
+							} else if isDurationExpr(s.Rhs[i], res) {
+								goType = "time.Duration"
+							}
+						}
+						res.Define(ident.Name, &Symbol{Name: ident.Name, GoType: goType})
+					}
+				}
+			}
+		}
+
+		if len(s.Lhs) == 1 && len(s.Rhs) == 1 {
+			// holder.Parent = p  →  weak_assign for //golden:weak fields
+			if sel, ok := s.Lhs[0].(*ast.SelectorExpr); ok && s.Tok == token.ASSIGN && isWeakSelector(sel, res) {
+				return []odin.Stmt{&odin.ExprStmt{X: translateWeakStore(sel, s.Rhs[0], res)}}
+			}
+			// holder.Child = c  →  arc_store for strong pointer fields
+			if sel, ok := s.Lhs[0].(*ast.SelectorExpr); ok && s.Tok == token.ASSIGN && isStrongSelector(sel, res) {
+				return []odin.Stmt{&odin.ExprStmt{X: translateStrongStore(sel, s.Rhs[0], res)}}
+			}
+
+			lhs := translateExpr(s.Lhs[0], res)
+			varName := odin.ExprString(lhs)
+			tok := s.Tok.String()
+
+			// Slices, maps and heap strings (arena or owned, see heap.go)
+			if _, isIdent := s.Lhs[0].(*ast.Ident); isIdent {
+				if kind := assignHeapKind(varName, s.Rhs[0], getParentFunc(s, res.File), res); kind != heapNone {
+					return translateHeapAssign(s, varName, kind, res)
+				}
+			}
+
+			// c := sync.NewCond(&mu): the Cond is heap allocated and owned here
+			if call, ok := s.Rhs[0].(*ast.CallExpr); ok && s.Tok == token.DEFINE && exprToStrBasic(call.Fun) == "sync.NewCond" {
+				out := []odin.Stmt{odin.NewAssign(lhs, tok, translateExpr(call, res))}
+				if !movesOut(varName, getParentFunc(s, res.File)) {
+					out = append(out, odin.DeferCall("free", lhs))
+				}
+				return out
+			}
+			// n := Node{Child: c}: a struct value holding ARC handles drops them
+			if lit, ok := s.Rhs[0].(*ast.CompositeLit); ok && s.Tok == token.DEFINE && lit.Type != nil && hasDropProc(mapType(lit.Type, res), res) && !movesOut(varName, getParentFunc(s, res.File)) {
+				return []odin.Stmt{
+					odin.NewAssign(lhs, tok, translateExpr(lit, res)),
+					odin.DeferCall(mapType(lit.Type, res)+"_drop", odin.AddrOf(lhs)),
+				}
+			}
+			// t := time.NewTimer(d): the timer goes back to the runtime at scope end
+			if call, ok := s.Rhs[0].(*ast.CallExpr); ok && s.Tok == token.DEFINE {
+				if drop, ok := timerDrop(varName, call, getParentFunc(s, res.File)); ok {
+					return []odin.Stmt{odin.NewAssign(lhs, tok, translateExpr(call, res)), odin.DeferCall(drop, lhs)}
+				}
+			}
+			if lit, ok := s.Rhs[0].(*ast.CompositeLit); ok && s.Tok == token.DEFINE && mapType(lit.Type, res) == "golden.Sync_Pool" {
+				return []odin.Stmt{
+					odin.NewAssign(lhs, tok, translateExpr(lit, res)),
+					odin.DeferCall("golden.sync_pool_destroy", odin.AddrOf(lhs)),
+				}
+			}
+
+			sym, exists := res.Lookup(varName)
+
+			if unary, ok := s.Rhs[0].(*ast.UnaryExpr); ok && unary.Op == token.AND {
+				if lit, ok := unary.X.(*ast.CompositeLit); ok {
+					litExpr := handleCompositeLit(lit, res)
+					typeName := mapType(lit.Type, res)
+
+					if exists && sym.Strategy == AllocArena {
+						out := []odin.Stmt{
+							odin.NewAssign(lhs, tok, odin.NewCall("golden.frame_new", &odin.CompositeLit{Type: typeName}, odin.AddrOf(odin.NewIdent("_frame")))),
+							odin.CallStmt("golden.frame_init", lhs, litExpr),
+						}
+						if hasDropProc(typeName, res) {
+							out = append(out, odin.DeferCall(typeName+"_drop", lhs))
+						}
+						return out
+					} else {
+						// ARC Logic
+						assign := odin.NewAssign(lhs, tok, odin.NewCall("golden.make_arc", append([]odin.Expr{litExpr}, dropArg(typeName, res)...)...))
+						return append([]odin.Stmt{assign}, arcReleaseDefer(varName, s, res)...)
+					}
+				}
+			}
+
+			if call, ok := s.Rhs[0].(*ast.CallExpr); ok {
+				funcName := exprToStrBasic(call.Fun)
+				if funcName == "append" {
+					args := []odin.Expr{odin.AddrOf(translateExpr(call.Args[0], res))}
+					for i := 1; i < len(call.Args); i++ {
+						args = append(args, translateExpr(call.Args[i], res))
+					}
+					return []odin.Stmt{odin.CallStmt("append", args...)}
+				}
+				if funcName == "make" {
+					if chanType, isChan := call.Args[0].(*ast.ChanType); isChan {
+						res.Define(varName, &Symbol{
+							Name:     varName,
+							GoType:   "^golden.Channel(" + mapType(chanType.Value, res) + ")",
+							Strategy: AllocNone,
+						})
+						assign := odin.NewAssign(lhs, tok, odin.NewCall("golden.chan_make", &odin.TypeExpr{Type: mapType(chanType.Value, res)}))
+						return []odin.Stmt{assign, odin.DeferCall("free", lhs)}
+					}
+				}
+				// Handle normal function calls returning ARC pointers
+				if retTypeName, ok := res.funcReturnTypes[funcName]; ok {
+					res.Define(varName, &Symbol{Name: varName, GoType: retTypeName, Strategy: AllocARC})
+					assign := odin.NewAssign(lhs, tok, handleCallWithResolver(call, res))
+					return append([]odin.Stmt{assign}, arcReleaseDefer(varName, s, res)...)
+				}
+			}
+
+			// y := x on an ARC owner — y becomes an owner too (move or retain)
+			if src, ok := isArcOwner(s.Rhs[0], res); ok && s.Tok == token.DEFINE {
+				srcSym, _ := res.Lookup(src)
+				res.Define(varName, &Symbol{Name: varName, GoType: srcSym.GoType, Strategy: AllocARC})
+				assign := odin.NewAssign(lhs, ":=", arcHandOff(src, s, res))
+				return append([]odin.Stmt{assign}, arcReleaseDefer(varName, s, res)...)
+			}
+		}
+
+		assign := &odin.Assign{Op: s.Tok.String()}
+		var defers []odin.Stmt // Array to hold our injected cleanups
+
+		for _, l := range s.Lhs {
+			lhs := translateExpr(l, res)
+			assign.Lhs = append(assign.Lhs, lhs)
+			// FIX 3: If the variable looks like an error, auto-delete it at end of scope
+			// In Odin, `delete(nil)` is perfectly safe, so this works flawlessly.
+			if strings.Contains(odin.ExprString(lhs), "err") && !(len(s.Rhs) == 1 && isContextErr(s.Rhs[0], res)) {
+				defers = append(defers, odin.DeferCall("delete", lhs))
+			}
+		}
+		for i, r := range s.Rhs {
+			// An owned value stored where it outlives the function moves there
+			if name, ok := ownedVar(r, res); ok && len(s.Lhs) == len(s.Rhs) && outlivesFunc(s.Lhs[i], getParentFunc(s, res.File), res) {
+				assign.Rhs = append(assign.Rhs, takeOwned(name))
+				continue
+			}
+			assign.Rhs = append(assign.Rhs, translateExpr(r, res))
+		}
+
+		return append([]odin.Stmt{assign}, defers...) // Inject our cleanups right after the assignment
+
+	case *ast.DeclStmt:
+		return translateDecl(s.Decl, res)
+	case *ast.ExprStmt:
+		if call, ok := s.X.(*ast.CallExpr); ok {
+			if out, ok := translateSyncStmt(call, res); ok {
+				return out
+			}
+			if ownedCall(call, res) {
+				// Nothing takes the result
+				return []odin.Stmt{odin.CallStmt("delete", handleCallWithResolver(call, res))}
+			}
+			return []odin.Stmt{&odin.ExprStmt{X: handleCallWithResolver(call, res)}}
+		}
+		return []odin.Stmt{&odin.ExprStmt{X: translateExpr(s.X, res)}}
+	case *ast.ReturnStmt:
+		if len(s.Results) == 0 {
+			return []odin.Stmt{&odin.Return{}}
+		}
+		fn := getParentFunc(s, res.File)
+		ret := &odin.Return{}
+		for _, r := range s.Results {
+			// Hand ARC owners to the caller unless the defer was elided
+			if name, ok := isArcOwner(r, res); ok && !returnedOnEveryPath(name, fn) {
+				ret.Results = append(ret.Results, odin.NewCall("golden.arc_move", odin.AddrOf(odin.NewIdent(name))))
+				continue
+			}
+			// ...and owned heap values likewise
+			if name, ok := ownedVar(r, res); ok {
+				ret.Results = append(ret.Results, takeOwned(name))
+				continue
+			}
+			if ident, ok := r.(*ast.Ident); ok && ident.Name == "nil" && res.ReturnsArc {
+				ret.Results = append(ret.Results, &odin.CompositeLit{}) // an empty handle
+				continue
+			}
+			ret.Results = append(ret.Results, translateExpr(r, res))
+		}
+		// Owned results are handed to the caller on context.allocator
+		if fd, ok := fn.(*ast.FuncDecl); ok && res.funcReturnsOwned[fd.Name.Name] != "" {
+			if kind := classifyHeap(s.Results[0], res); kind != heapNone {
+				ret.Results[0] = heapAllocExpr(s.Results[0], kind, nil, res)
+			}
+		}
+		return []odin.Stmt{ret}
+	case *ast.IfStmt:
+		return []odin.Stmt{translateIfWithResolver(s, res)}
+	case *ast.ForStmt:
+		return translateForWithResolver(s, res)
+	case *ast.RangeStmt:
+		return []odin.Stmt{translateRangeWithResolver(s, res)}
+	case *ast.DeferStmt:
+		return []odin.Stmt{&odin.Defer{Stmt: &odin.ExprStmt{X: handleCallWithResolver(s.Call, res)}}}
+	case *ast.IncDecStmt:
+		op := "+="
+		if s.Tok == token.DEC {
+			op = "-="
+		}
+		return []odin.Stmt{odin.NewAssign(translateExpr(s.X, res), op, odin.IntLit(1))}
+	case *ast.BlockStmt:
+		res.EnterScope()
+		defer res.ExitScope()
+		return []odin.Stmt{&odin.Block{Body: translateBody(s.List, res)}}
+	case *ast.GoStmt:
+		return translateGoStmtWithResolver(s, res)
+	case *ast.SelectStmt:
+		return translateSelect(s, res)
+	case *ast.SendStmt:
+		return []odin.Stmt{odin.CallStmt("golden.chan_send", translateExpr(s.Chan, res), translateExpr(s.Value, res))}
+	case *ast.BranchStmt:
+		if s.Tok == token.BREAK || s.Tok == token.CONTINUE {
+			out := &odin.Branch{Tok: s.Tok.String()}
+			if s.Label != nil {
+				out.Label = s.Label.Name
+			}
+			return []odin.Stmt{out}
+		}
+	}
+	res.report(stmt.Pos(), Error, CatStatement, nodeKind(stmt), "unsupported %s", nodeKind(stmt))
+	return []odin.Stmt{&odin.Comment{Text: "unsupported " + nodeKind(stmt)}}
+}
+
+func translateIfWithResolver(s *ast.IfStmt, res *Resolver) *odin.If {
+	out := &odin.If{Cond: translateExpr(s.Cond, res)}
+
+	res.EnterScope()
+	out.Body = translateBody(s.Body.List, res)
+	res.ExitScope()
+
+	switch el := s.Else.(type) {
+	case *ast.IfStmt:
+		out.Else = translateIfWithResolver(el, res)
+	case *ast.BlockStmt:
+		res.EnterScope()
+		out.Else = &odin.Block{Body: translateBody(el.List, res)}
+		res.ExitScope()
+	}
+	return out
+}
+
+func translateForWithResolver(s *ast.ForStmt, res *Resolver) []odin.Stmt {
+	res.EnterScope()
+	defer res.ExitScope()
+
+	loop := &odin.For{}
+	if s.Init == nil && s.Post == nil {
+		if s.Cond != nil {
+			loop.Cond = translateExpr(s.Cond, res)
+		}
+		loop.Body = translateBody(s.Body.List, res)
+		return []odin.Stmt{loop}
+	}
+
+	var init, post []odin.Stmt
+	if s.Init != nil {
+		init = translateStmtWithResolver(s.Init, res)
+	}
+	if s.Post != nil {
+		post = translateStmtWithResolver(s.Post, res)
+	}
+	if s.Cond != nil {
+		loop.Cond = translateExpr(s.Cond, res)
+	}
+
+	// Odin's three-clause loop keeps `continue` running the post statement
+	// and scopes the loop variable to the loop
+	if len(init) <= 1 && len(post) <= 1 {
+		if len(init) == 1 {
+			loop.Init = init[0]
+		}
+		if len(post) == 1 {
+			loop.Post = post[0]
+		}
+		loop.Body = translateBody(s.Body.List, res)
+		return []odin.Stmt{loop}
+	}
+
+	// Otherwise the body gets a block of its own and the post statements
+	// follow it
+	if s.Post != nil {
+		post = markFirst(s.Post.Pos(), post)
+	}
+	loop.Body = append([]odin.Stmt{&odin.Block{Body: translateBody(s.Body.List, res)}}, post...)
+	return append(init, loop)
+}
+
+func translateRangeWithResolver(s *ast.RangeStmt, res *Resolver) *odin.ForIn {
+	key, val := odin.Expr(odin.NewIdent("_")), odin.Expr(odin.NewIdent("_"))
+	if s.Key != nil {
+		key = translateExpr(s.Key, res)
+	}
+	if s.Value != nil {
+		val = translateExpr(s.Value, res)
+	}
+	loop := &odin.ForIn{Vars: []odin.Expr{val, key}, X: translateExpr(s.X, res)}
+
+	res.EnterScope()
+	loop.Body = translateBody(s.Body.List, res)
+	res.ExitScope()
+	return loop
+}
+
+func translateDecl(decl ast.Decl, res *Resolver) []odin.Stmt {
+	gd, ok := decl.(*ast.GenDecl)
+	if !ok {
+		return nil
+	}
+	var out []odin.Stmt
+	for _, spec := range gd.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+
+		mappedType := "" // We need to store this for the Resolver!
+		isSyncWG := false
+
+		if vs.Type != nil {
+			mappedType = mapType(vs.Type, res)
+			if sel, ok := vs.Type.(*ast.SelectorExpr); ok {
+				if exprToStrBasic(sel.X) == "sync" && sel.Sel.Name == "WaitGroup" {
+					isSyncWG = true
+				}
+			}
+		}
+
+		for i, name := range vs.Names {
+			if mappedType == "golden.Sync_Map" {
+				mappedType = syncMapType(mappedType, name.Name, res)
+			}
+			if at, ok := vs.Type.(*ast.ArrayType); ok && at.Len == nil && len(vs.Values) == 0 {
+				out = append(out, translateHeapDecl(name.Name, mappedType, gd, res)...)
+				continue
+			}
+			v := &odin.VarDecl{Name: name.Name, Type: mappedType}
+			if i < len(vs.Values) {
+				v.Value = translateExpr(vs.Values[i], res)
+			}
+			out = append(out, v)
+			ref := odin.AddrOf(odin.NewIdent(name.Name))
+			if isSyncWG {
+				out = append(out, odin.CallStmt("golden.wg_init", ref))
+			}
+			if strings.HasPrefix(mappedType, "golden.Sync_Map(") {
+				out = append(out, odin.DeferCall("golden.sync_map_destroy", ref))
+			} else if mappedType == "golden.Sync_Pool" {
+				out = append(out, odin.DeferCall("golden.sync_pool_destroy", ref))
+			}
+
+			// FIX: Actually register the GoType so closures can resolve it!
+			res.Define(name.Name, &Symbol{Name: name.Name, GoType: mappedType})
+		}
+	}
+	return out
+}
+
+func exprToStrBasic(expr ast.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		return exprToStrBasic(sel.X) + "." + sel.Sel.Name
+	}
+	return ""
+}
+
+func translateExpr(expr ast.Expr, res *Resolver) odin.Expr {
+	if expr == nil {
+		return nil
+	}
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return odin.NewIdent(e.Name)
+	case *ast.BasicLit:
+		return &odin.BasicLit{Value: e.Value}
+	case *ast.BinaryExpr:
+		if isRuntimeConcat(e, res) {
+			// Unbound concatenation (e.g. a call argument) lives on the temp allocator
+			return translateConcat(e, odin.NewIdent("context.temp_allocator"), res)
+		}
+		return &odin.Binary{X: translateExpr(e.X, res), Op: mapOperator(e.Op), Y: translateExpr(e.Y, res)}
+	case *ast.UnaryExpr:
+		if e.Op == token.ARROW {
+			return odin.NewCall("golden.chan_recv", translateExpr(e.X, res))
+		}
+		return &odin.Unary{Op: e.Op.String(), X: translateExpr(e.X, res)}
+	case *ast.ParenExpr:
+		// The printer puts back the parentheses Odin needs
+		return translateExpr(e.X, res)
+	case *ast.SelectorExpr:
+		if isWeakSelector(e, res) {
+			return odin.NewCall("golden.weak_get", translateSelector(e, res))
+		}
+		if isStrongSelector(e, res) {
+			return odin.FieldOf(translateSelector(e, res), "data") // borrowed
+		}
+		if out, ok := translateContextSelector(e, res); ok {
+			return out
+		}
+		return translateSelector(e, res)
+	case *ast.IndexExpr:
+		return &odin.Index{X: translateExpr(e.X, res), Index: translateExpr(e.Index, res)}
+	case *ast.CallExpr:
+		return handleCallWithResolver(e, res)
+	case *ast.CompositeLit:
+		return handleCompositeLit(e, res)
+	case *ast.TypeAssertExpr:
+		if out, ok := translateContextAssert(e, res); ok {
+			return out
+		}
+		inner := translateExpr(e.X, res)
+		if call, ok := inner.(*odin.Call); ok && odin.ExprString(call.Fun) == "golden.sync_pool_get" {
+			// Pool items are untyped pointers
+			return &odin.Cast{Type: mapType(e.Type, res), X: inner}
+		}
+		res.report(e.Pos(), Error, CatExpression, "type assertion", "unsupported type assertion %s", types.ExprString(e))
+		return &odin.Bad{Comment: "unsupported type assertion", X: inner}
+	case *ast.SliceExpr:
+		return &odin.Slice{X: translateExpr(e.X, res), Lo: translateExpr(e.Low, res), Hi: translateExpr(e.High, res)}
+	case *ast.ArrayType, *ast.MapType:
+		return &odin.TypeExpr{Type: mapType(e, res)}
+	case *ast.FuncLit:
+		return translateFuncLit(e, res)
+	}
+	res.report(expr.Pos(), Error, CatExpression, nodeKind(expr), "unsupported expression %s", types.ExprString(expr))
+	return &odin.Bad{Comment: "unsupported " + nodeKind(expr)}
+}
+
+// translateFuncLit emits a func literal as an Odin proc literal. Odin procs
+// don't capture, so this only fits literals that use nothing from the
+// enclosing function, like sync.Pool's New. An `any` result becomes rawptr.
+func translateFuncLit(fn *ast.FuncLit, res *Resolver) *odin.ProcLit {
+	res.EnterScope()
+	defer res.ExitScope()
+
+	proc := &odin.ProcLit{}
+	for _, field := range fn.Type.Params.List {
+		t := mapType(field.Type, res)
+		for _, name := range field.Names {
+			proc.Params = append(proc.Params, odin.Field{Name: name.Name, Type: t})
+			res.Define(name.Name, &Symbol{Name: name.Name, GoType: t})
+		}
+	}
+	if fn.Type.Results != nil && len(fn.Type.Results.List) == 1 {
+		t := mapType(fn.Type.Results.List[0].Type, res)
+		if t == "any" {
+			t = "rawptr"
+		}
+		proc.Results = []string{t}
+	}
+	proc.Body = translateBody(fn.Body.List, res)
+	return proc
+}
+
+// translateSelector emits a field access, dereferencing ARC handles.
+func translateSelector(e *ast.SelectorExpr, res *Resolver) odin.Expr {
+	base := translateExpr(e.X, res)
+	if ident, ok := e.X.(*ast.Ident); ok {
+		if sym, ok := res.Lookup(ident.Name); ok && sym.Strategy == AllocARC {
+			return odin.FieldOf(odin.FieldOf(base, "data"), e.Sel.Name) // ARC Deref
+		}
+	}
+	return odin.FieldOf(base, e.Sel.Name)
+}
+
+func mapOperator(op token.Token) string {
+	switch op {
+	case token.ADD:
+		return "+"
+	case token.SUB:
+		return "-"
+	case token.MUL:
+		return "*"
+	case token.QUO:
+		return "/"
+	case token.REM:
+		return "%%"
+	case token.EQL:
+		return "=="
+	case token.NEQ:
+		return "!="
+	case token.LSS:
+		return "<"
+	case token.GTR:
+		return ">"
+	case token.LEQ:
+		return "<="
+	case token.GEQ:
+		return ">="
+	case token.LAND:
+		return "&&"
+	case token.LOR:
+		return "||"
+	case token.AND:
+		return "&"
+	case token.OR:
+		return "|"
+	case token.XOR:
+		return "~"
+	case token.SHL:
+		return "<<"
+	case token.SHR:
+		return ">>"
+	default:
+		return op.String()
+	}
+}
+
+var funcMap = map[string]string{
+	"fmt.Println": "fmt.println",
+	"fmt.Printf":  "fmt.printf",
+	"fmt.Sprintf": "fmt.tprintf",
+	"fmt.Print":   "fmt.print",
+	"fmt.Fprintf": "fmt.fprintln",
+	"len":         "len",
+	"cap":         "cap",
+	"make":        "make",
+	"append":      "append",
+	"delete":      "delete",
+	"panic":       "panic",
+	"new":         "new",
+}
+
+func handleCallWithResolver(call *ast.CallExpr, res *Resolver) odin.Expr {
+	if tmp, ok := res.ownedTemps[call]; ok {
+		return odin.NewIdent(tmp)
+	}
+	funcNameBasic := exprToStrBasic(call.Fun)
+
+	// Channel Make Hook
+	if funcNameBasic == "make" && len(call.Args) > 0 {
+		if chanType, isChan := call.Args[0].(*ast.ChanType); isChan {
+			return odin.NewCall("golden.chan_make", &odin.TypeExpr{Type: mapType(chanType.Value, res)})
+		}
+	}
+
+	// cancel() on a context.CancelFunc
+	if isCancelCall(call, res) {
+		return odin.NewCall("golden.cancel_call", translateExpr(call.Fun, res))
+	}
+
+	// FIX 2: Intercept global overrides like 'errors.New' BEFORE treating them as struct methods
+	if mapped, ok := funcMap[funcNameBasic]; ok {
+		out := odin.NewCall(mapped, callArgs(call, res)...)
+		out.Ellipsis = call.Ellipsis.IsValid()
+		return out
+	}
+
+	// Parse Standard Struct Methods
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+		method := sel.Sel.Name
+		recv := translateExpr(sel.X, res)
+		recvBase := exprToStrBasic(sel.X)
+
+		// 1. Check if it's an imported package like "os"
+		if path, isImported := res.Imports[recvBase]; isImported {
+			if out, ok := translateSyncFunc(path, method, call, res); ok {
+				return out
+			}
+			if path == "time" {
+				if out, ok := translateTimeFunc(method, call, res); ok {
+					return out
+				}
+			}
+			if path == "context" {
+				if out, ok := translateContextFunc(method, call, res); ok {
+					return out
+				}
+			}
+			if recvBase == "os" {
+				var args []odin.Expr
+				for _, arg := range call.Args {
+					args = append(args, translateExpr(arg, res))
+				}
+				return odin.NewCall("golden_os."+toSnakeCase(method), args...)
+			}
+			if _, mapped := res.Opts.Packages[path]; mapped {
+				return odin.NewCall(recvBase+"."+toSnakeCase(method), callArgs(call, res)...)
+			}
+			res.report(call.Pos(), Error, CatStdlib, path+"."+method, "no Odin translation for %s.%s", path, method)
+			return odin.NewCall(funcNameBasic, callArgs(call, res)...)
+		}
+
+		// 2. sync / sync/atomic values, dispatched on the receiver type
+		if out, ok := translateSyncMethod(sel, call, res); ok {
+			return out
+		}
+		if out, ok := translateTimeMethod(sel, call, res); ok {
+			return out
+		}
+		if out, ok := translateContextMethod(sel, call, res); ok {
+			return out
+		}
+
+		// 3. Standard Method Call (Skipping standard packages)
+		if recvBase != "fmt" && recvBase != "strings" && recvBase != "math" {
+			structType := ""
+			var args []odin.Expr
+			isPtr, known := res.methodIsPointer[method]
+
+			if sym, ok := res.Lookup(recvBase); ok {
+				// 1. Resolve Struct Name
+				structType = strings.TrimPrefix(sym.GoType, "golden.Arc(")
+				structType = strings.TrimSuffix(structType, ")")
+				structType = strings.TrimPrefix(structType, "^")
+
+				// 2. Resolve Receiver Argument
+				isAlreadyPtr := strings.HasPrefix(sym.GoType, "^")
+
+				if sym.Strategy == AllocARC {
+					if known && !isPtr {
+						args = append(args, &odin.Deref{X: odin.FieldOf(recv, "data")}) // Pass by value
+					} else {
+						args = append(args, odin.FieldOf(recv, "data")) // Pass the pointer
+					}
+				} else if isAlreadyPtr {
+					// If it's already a pointer, just pass it. No '&' needed.
+					args = append(args, recv)
+				} else {
+					// It's a value. Only add '&' if the method is known to want a pointer.
+					if known && !isPtr {
+						args = append(args, recv)
+					} else {
+						args = append(args, odin.AddrOf(recv))
+					}
+				}
+			} else {
+				// Fallback for unknown symbols
+				structType = strings.ToUpper(recvBase[:1]) + recvBase[1:]
+				args = append(args, odin.AddrOf(recv))
+			}
+
+			return odin.NewCall(structType+"_"+method, append(args, callArgs(call, res)...)...)
+		}
+	}
+
+	// Unmapped Package Functions / Global Functions
+	out := odin.NewCall(funcNameBasic, callArgs(call, res)...)
+	out.Ellipsis = call.Ellipsis.IsValid()
+	return out
+}
+
+func handleCompositeLit(lit *ast.CompositeLit, res *Resolver) odin.Expr {
+	out := &odin.CompositeLit{Multiline: len(lit.Elts) > 3}
+	if lit.Type != nil {
+		out.Type = mapType(lit.Type, res)
+	}
+
+	// Keys of struct literals name fields; those of map and array
+	// literals are values
+	keyed := false
+	switch lit.Type.(type) {
+	case *ast.MapType, *ast.ArrayType:
+		keyed = true
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			out.Elts = append(out.Elts, translateExpr(elt, res))
+			continue
+		}
+		if field, ok := kv.Key.(*ast.Ident); ok && !keyed {
+			var value odin.Expr
+			if isStrongType(res.structFields[out.Type][field.Name]) {
+				if ident, ok := kv.Value.(*ast.Ident); ok && ident.Name == "nil" {
+					continue // a zero handle
+				}
+				value = strongValue(kv.Value, kv, res)
+			} else {
+				value = translateExpr(kv.Value, res)
+			}
+			out.Elts = append(out.Elts, &odin.FieldValue{Name: field.Name, Value: value})
+			continue
+		}
+		out.Elts = append(out.Elts, &odin.KeyValue{Key: translateExpr(kv.Key, res), Value: translateExpr(kv.Value, res)})
+	}
+	return out
+}
+
+// ── Dynamic Goroutine Capture Walker ──────────────────────────────────
+
+// goCtx names a goroutine wrapper's closure pointer. The leading
+// underscore keeps it clear of the usual Go names, ctx above all, that
+// the goroutine body may declare itself.
+const goCtx = "_gctx"
+
+// CaptureInfo holds the type mapping for the closure struct generator
+type CaptureInfo struct {
+	Type     string
+	IsPtrRef bool
+	Deref    bool      // shared plain value: read through the pointer as _gctx.v^
+	IsArc    bool      // the goroutine holds its own reference (moved or retained)
+	Arg      odin.Expr // goroutine parameter: evaluated here instead of copied
+}
+
+func translateGoStmtWithResolver(s *ast.GoStmt, res *Resolver) []odin.Stmt {
+	if fn, params, ok := goClosure(s.Call, res); ok {
+		capturedVars := make(map[string]CaptureInfo)
+		free := freeIdents(fn)
+		// Variables whose address the goroutine takes (&total) are shared
+		addressed := make(map[string]bool)
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if u, ok := n.(*ast.UnaryExpr); ok && u.Op == token.AND {
+				if ident, ok := u.X.(*ast.Ident); ok && free[ident] {
+					addressed[ident.Name] = true
+				}
+			}
+			return true
+		})
+
+		for ident := range free {
+			name := ident.Name
+			if name == "true" || name == "false" || name == "nil" {
+				continue
+			}
+			if _, isFunc := funcMap[name]; isFunc {
+				continue
+			}
+
+			if sym, ok := res.Lookup(name); ok && !sym.IsGlobal {
+				t := sym.GoType
+				isPtrRef := false
+
+				deref := false
+				if isSharedSyncType(t) || hasSyncFields(t, res) {
+					t = "^" + t
+					isPtrRef = true
+				} else if addressed[name] && !isPointerType(t) && sym.Strategy != AllocARC {
+					t = "^" + t
+					isPtrRef = true
+					deref = true
+				}
+				if sym.Strategy == AllocARC {
+					capturedVars[name] = CaptureInfo{Type: "golden.Arc(" + t + ")", IsArc: true}
+					continue
+				}
+				capturedVars[name] = CaptureInfo{Type: t, IsPtrRef: isPtrRef, Deref: deref}
+			}
+		}
+		for _, p := range params {
+			capturedVars[p.Name] = CaptureInfo{Type: p.Type, Arg: p.Arg}
+		}
+
+		// Captures in name order, then the parameters in theirs, so the
+		// output is reproducible and arguments are evaluated left to right
+		var captures []string
+		for v, info := range capturedVars {
+			if info.Arg == nil {
+				captures = append(captures, v)
+			}
+		}
+		sort.Strings(captures)
+		for _, p := range params {
+			captures = append(captures, p.Name)
+		}
+
+		structName := posName("_closure_ctx", s.Go, res)
+		wrapperName := posName("_go_wrapper", s.Go, res)
+		ctxVar := odin.NewIdent(posName("_ctx", s.Go, res))
+
+		ctxStruct := &odin.StructDecl{Name: structName}
+		for _, v := range captures {
+			ctxStruct.Fields = append(ctxStruct.Fields, odin.Field{Name: v, Type: capturedVars[v].Type})
+		}
+		out := []odin.Stmt{ctxStruct, odin.NewAssign(ctxVar, ":=", odin.NewCall("new", odin.NewIdent(structName)))}
+
+		for _, v := range captures {
+			info := capturedVars[v]
+			var value odin.Expr
+			if info.Arg != nil {
+				value = info.Arg
+			} else if info.IsPtrRef {
+				value = odin.AddrOf(odin.NewIdent(v))
+			} else if info.IsArc {
+				value = arcHandOff(v, s, res)
+			} else {
+				value = odin.NewIdent(v)
+			}
+			out = append(out, odin.NewAssign(odin.FieldOf(ctxVar, v), "=", value))
+		}
+
+		res.EnterScope()
+		for _, p := range params {
+			res.Define(p.Name, &Symbol{Name: p.Name, GoType: p.Type})
+		}
+		needsFrame := prepareBody(fn.Body, res)
+		body := translateBody(fn.Body.List, res)
+		res.ExitScope()
+		if needsFrame {
+			// A per-task arena, released when the goroutine returns
+			body = append(frameBegin(), body...)
+		}
+
+		ctx := odin.NewIdent(goCtx)
+		wrapper := &odin.ProcDecl{
+			Name:   wrapperName,
+			Params: []odin.Field{{Name: "data", Type: "rawptr"}},
+			Body: []odin.Stmt{
+				odin.NewAssign(ctx, ":=", &odin.Cast{Type: "^" + structName, X: odin.NewIdent("data")}),
+				// The body gets its own scope so its defers (wg.Done()) run while
+				// the closure context is still alive
+				&odin.Block{Body: captureRefs(body, params, capturedVars)},
+			},
+		}
+		// Drop the goroutine's ARC references before the context goes
+		for _, v := range captures {
+			if capturedVars[v].IsArc {
+				wrapper.Body = append(wrapper.Body, odin.CallStmt("golden.arc_release", odin.AddrOf(odin.FieldOf(ctx, v))))
+			}
+		}
+		// The task runs with the spawner's context.allocator (see spawn_raw)
+		wrapper.Body = append(wrapper.Body, odin.CallStmt("free", ctx))
+
+		return append(out, wrapper, odin.CallStmt("golden.spawn_raw", odin.NewIdent(wrapperName), ctxVar, goPosLiteral(s.Go, wrapperName, res)))
+	}
+	res.report(s.Pos(), Error, CatGoroutine, fmt.Sprintf("go statement (%s)", nodeKind(s.Call.Fun)), "unsupported go statement: go %s", types.ExprString(s.Call))
+	return []odin.Stmt{&odin.Comment{Text: "unsupported go statement"}}
+}
+
+// captureRefs points a goroutine body's uses of captured variables at the
+// closure context: v becomes _gctx.v, or _gctx.v^ for a shared plain value,
+// and &v of a variable held by pointer is _gctx.v itself. Names that only look
+// alike — fields, labels, text in strings, locals of the goroutine that
+// shadow a capture — are left alone.
+func captureRefs(body []odin.Stmt, params []goParam, captured map[string]CaptureInfo) []odin.Stmt {
+	ctxField := func(v string) odin.Expr {
+		return odin.FieldOf(odin.NewIdent(goCtx), v)
+	}
+	var paramNames []string
+	for _, p := range params {
+		paramNames = append(paramNames, p.Name)
+	}
+	return odin.RewriteFree(body, paramNames, func(e odin.Expr, local func(string) bool) (odin.Expr, bool) {
+		switch x := e.(type) {
+		case *odin.Unary:
+			if ident, ok := x.X.(*odin.Ident); ok && x.Op == "&" && !local(ident.Name) {
+				if info, ok := captured[ident.Name]; ok && (info.IsPtrRef || isPointerType(info.Type)) {
+					return ctxField(ident.Name), true
+				}
+			}
+		case *odin.Ident:
+			if info, ok := captured[x.Name]; ok && !local(x.Name) {
+				if info.Deref {
+					return &odin.Deref{X: ctxField(x.Name)}, true
+				}
+				return ctxField(x.Name), true
+			}
+		}
+		return e, false
+	})
+}
+
+func init() {
+	funcMap["errors.New"] = "golden.error_new"
+	funcMap["close"] = "golden.chan_close"
+}
+
+func toSnakeCase(str string) string {
+	var b strings.Builder
+	for i, r := range str {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteRune('_')
+		}
+		b.WriteRune(r | 0x20)
+	}
+	return b.String()
+}
+
+func isReturningVar(name string, scopeStmt ast.Node) bool {
+	isReturned := false
+
+	// Walk the current function or block to see if 'name' is used in a ReturnStmt
+	ast.Inspect(scopeStmt, func(n ast.Node) bool {
+		if ret, ok := n.(*ast.ReturnStmt); ok {
+			for _, res := range ret.Results {
+				if ident, ok := res.(*ast.Ident); ok && ident.Name == name {
+					isReturned = true
+					return false // Found it, stop looking
+				}
+			}
+		}
+		return true
+	})
+
+	return isReturned
+}
+
+func getParentFunc(n ast.Node, file *ast.File) ast.Node {
+	var parent ast.Node
+	ast.Inspect(file, func(child ast.Node) bool {
+		if fd, ok := child.(*ast.FuncDecl); ok {
+			// Check if n is inside fd
+			if n.Pos() >= fd.Pos() && n.End() <= fd.End() {
+				parent = fd
+			}
+		}
+		return true
+	})
+	return parent
+}
@@ -0,0 +1,251 @@
+// --- golden/transpiler/weak.go ---
+
+package transpiler
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/v4rm4n/golden/transpiler/internal/odin"
+)
+
+// ── Weak References ──────────────────────────────────────────────────────────
+//
+// A pointer field to a struct of the file is a strong golden.Arc(T) handle;
+// one annotated with //golden:weak becomes golden.Weak(T):
+//
+//	type Node struct {
+//		Child  *Node // golden.Arc(Node)
+//		Parent *Node //golden:weak
+//	}
+//
+// Strong fields keep their target alive, weak ones don't, so back-pointers
+// no longer form ARC cycles. Each struct holding either gets a generated
+// <Struct>_drop proc that releases them; ARC allocations run it before the
+// data is freed, arena allocations and struct values defer it.
+
+const weakAnnotation = "//golden:weak"
+
+// isWeakField reports whether a struct field carries //golden:weak.
+func isWeakField(field *ast.Field) bool {
+	for _, cg := range []*ast.CommentGroup{field.Doc, field.Comment} {
+		if cg == nil {
+			continue
+		}
+		for _, c := range cg.List {
+			if strings.HasPrefix(c.Text, weakAnnotation) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldType maps a struct field, making struct pointers ARC handles
+// (weak under //golden:weak) and typing sync.Map fields from their Store calls.
+func fieldType(field *ast.Field, res *Resolver) string {
+	if star, ok := field.Type.(*ast.StarExpr); ok {
+		if isWeakField(field) {
+			return fmt.Sprintf("golden.Weak(%s)", mapType(star.X, res))
+		}
+		if ident, ok := star.X.(*ast.Ident); ok && isFileStruct(ident.Name, res) {
+			return fmt.Sprintf("golden.Arc(%s)", ident.Name)
+		}
+	}
+	t := mapType(field.Type, res)
+	if len(field.Names) > 0 {
+		t = syncMapType(t, field.Names[0].Name, res)
+	}
+	return t
+}
+
+// registerStructFields records field types for weak-field resolution.
+func registerStructFields(ts *ast.TypeSpec, res *Resolver) {
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return
+	}
+	fields := make(map[string]string)
+	for _, field := range st.Fields.List {
+		t := fieldType(field, res)
+		for _, name := range field.Names {
+			fields[name.Name] = t
+		}
+	}
+	res.structFields[ts.Name.Name] = fields
+}
+
+// isFileStruct reports whether name is a struct type declared in the file.
+func isFileStruct(name string, res *Resolver) bool {
+	for _, decl := range res.File.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == name {
+				_, isStruct := ts.Type.(*ast.StructType)
+				return isStruct
+			}
+		}
+	}
+	return false
+}
+
+// isStrongType reports whether an Odin field type is an owning ARC handle.
+func isStrongType(t string) bool {
+	return strings.HasPrefix(t, "golden.Arc(")
+}
+
+// borrowedType is the type a field read produces: strong fields are read
+// through their data pointer.
+func borrowedType(t string) string {
+	if isStrongType(t) {
+		return "^" + baseStructName(t)
+	}
+	return t
+}
+
+// dropFieldsOf returns the ARC handle fields of a struct, weak and strong,
+// in declaration order.
+func dropFieldsOf(ts *ast.TypeSpec, res *Resolver) (weak, strong []string) {
+	for _, field := range ts.Type.(*ast.StructType).Fields.List {
+		t := fieldType(field, res)
+		for _, name := range field.Names {
+			switch {
+			case strings.HasPrefix(t, "golden.Weak("):
+				weak = append(weak, name.Name)
+			case isStrongType(t):
+				strong = append(strong, name.Name)
+			}
+		}
+	}
+	return weak, strong
+}
+
+// hasDropProc reports whether a struct needs a <Struct>_drop proc.
+func hasDropProc(structName string, res *Resolver) bool {
+	for _, t := range res.structFields[structName] {
+		if strings.HasPrefix(t, "golden.Weak(") || isStrongType(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropProc emits the <Struct>_drop proc releasing a struct's ARC handles.
+func dropProc(structName string, weak, strong []string) *odin.ProcDecl {
+	self := odin.NewIdent("self")
+	proc := &odin.ProcDecl{
+		Name:   structName + "_drop",
+		Params: []odin.Field{{Name: "p", Type: "rawptr"}},
+		Body:   []odin.Stmt{odin.Define("self", &odin.Cast{Type: "^" + structName, X: odin.NewIdent("p")})},
+	}
+	for _, name := range weak {
+		proc.Body = append(proc.Body, odin.CallStmt("golden.weak_release", odin.AddrOf(odin.FieldOf(self, name))))
+	}
+	for _, name := range strong {
+		proc.Body = append(proc.Body, odin.CallStmt("golden.arc_release", odin.AddrOf(odin.FieldOf(self, name))))
+	}
+	return proc
+}
+
+// baseStructName strips pointer/ARC/Weak wrappers from an Odin type.
+func baseStructName(t string) string {
+	for _, wrap := range []string{"golden.Arc(", "golden.Weak("} {
+		if strings.HasPrefix(t, wrap) {
+			t = strings.TrimSuffix(strings.TrimPrefix(t, wrap), ")")
+		}
+	}
+	return strings.TrimPrefix(t, "^")
+}
+
+// exprStructType resolves the struct type an expression refers to.
+func exprStructType(expr ast.Expr, res *Resolver) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if sym, ok := res.Lookup(e.Name); ok {
+			return baseStructName(sym.GoType)
+		}
+	case *ast.SelectorExpr:
+		owner := exprStructType(e.X, res)
+		if fields, ok := res.structFields[owner]; ok {
+			return baseStructName(fields[e.Sel.Name])
+		}
+	case *ast.ParenExpr:
+		return exprStructType(e.X, res)
+	case *ast.StarExpr:
+		return exprStructType(e.X, res)
+	}
+	return ""
+}
+
+// isWeakSelector reports whether sel reads or writes a weak field.
+func isWeakSelector(sel *ast.SelectorExpr, res *Resolver) bool {
+	owner := exprStructType(sel.X, res)
+	return strings.HasPrefix(res.structFields[owner][sel.Sel.Name], "golden.Weak(")
+}
+
+// isStrongSelector reports whether sel reads or writes a strong field.
+func isStrongSelector(sel *ast.SelectorExpr, res *Resolver) bool {
+	return isStrongType(res.structFields[exprStructType(sel.X, res)][sel.Sel.Name])
+}
+
+// translateWeakStore emits `holder.field = target` for a weak field.
+func translateWeakStore(lhs *ast.SelectorExpr, rhs ast.Expr, res *Resolver) odin.Expr {
+	field := odin.AddrOf(translateSelector(lhs, res))
+	if ident, ok := rhs.(*ast.Ident); ok && ident.Name == "nil" {
+		return odin.NewCall("golden.weak_release", field)
+	}
+	if _, ok := isArcOwner(rhs, res); ok {
+		return odin.NewCall("golden.weak_assign", field, translateExpr(rhs, res))
+	}
+	if sel, ok := rhs.(*ast.SelectorExpr); ok && isStrongSelector(sel, res) {
+		return odin.NewCall("golden.weak_assign", field, translateSelector(sel, res))
+	}
+	res.report(rhs.Pos(), Error, CatExpression, "pointer field store",
+		"%s is not an ARC value; weak pointer fields can only refer to ARC allocations", types.ExprString(rhs))
+	return odin.NewCall("golden.weak_assign", field, translateExpr(rhs, res))
+}
+
+// translateStrongStore emits `holder.field = target` for a strong field:
+// the old target is released and the field takes its own reference.
+func translateStrongStore(lhs *ast.SelectorExpr, rhs ast.Expr, res *Resolver) odin.Expr {
+	field := odin.AddrOf(translateSelector(lhs, res))
+	if ident, ok := rhs.(*ast.Ident); ok && ident.Name == "nil" {
+		return odin.NewCall("golden.arc_release", field)
+	}
+	return odin.NewCall("golden.arc_store", field, strongValue(rhs, lhs, res))
+}
+
+// strongValue returns an owned reference to rhs for storing in a strong
+// field at node: ARC variables hand theirs off, fields are retained and
+// ARC-returning calls are moved in.
+func strongValue(rhs ast.Expr, node ast.Node, res *Resolver) odin.Expr {
+	if name, ok := isArcOwner(rhs, res); ok {
+		return arcHandOff(name, node, res)
+	}
+	switch e := rhs.(type) {
+	case *ast.SelectorExpr:
+		if isStrongSelector(e, res) {
+			return odin.NewCall("golden.retain", translateSelector(e, res))
+		}
+	case *ast.CallExpr:
+		if _, ok := res.funcReturnTypes[exprToStrBasic(e.Fun)]; ok {
+			return translateExpr(e, res)
+		}
+	}
+	res.report(rhs.Pos(), Error, CatExpression, "pointer field store",
+		"%s is not an ARC value; strong pointer fields can only hold ARC allocations", types.ExprString(rhs))
+	return translateExpr(rhs, res)
+}
+
+// dropArg returns the T_drop argument for make_arc when T holds ARC handles.
+func dropArg(structName string, res *Resolver) []odin.Expr {
+	if hasDropProc(structName, res) {
+		return []odin.Expr{odin.NewIdent(structName + "_drop")}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// --- golden/transpiler/weak_test.go ---
+
+package transpiler
+
+import "testing"
+
+func TestStrongAndWeakFields(t *testing.T) {
+	code := transpileSource(t, `package main
+
+import "fmt"
+
+type Node struct {
+	Val  int
+	Next *Node
+	//golden:weak
+	Parent *Node
+}
+
+func main() {
+	root := &Node{Val: 1}
+	child := &Node{Val: 2}
+	root.Next = child
+	child.Parent = root
+	fmt.Println(root.Next.Val)
+}
+`)
+	wantLines(t, code,
+		"Next: golden.Arc(Node),",
+		"Parent: golden.Weak(Node),",
+		"Node_drop :: proc(p: rawptr) {",
+		"golden.weak_release(&self.Parent)",
+		"golden.arc_release(&self.Next)",
+		"root := golden.make_arc(Node{Val = 1}, Node_drop)",
+		"child := golden.make_arc(Node{Val = 2}, Node_drop)",
+		"golden.arc_store(&root.data.Next, golden.retain(child))",
+		"golden.weak_assign(&child.data.Parent, root)",
+		"fmt.println(root.data.Next.data.Val)",
+	)
+}